@@ -0,0 +1,93 @@
+package predicates
+
+// AngleKind classifies an angle against a right angle.
+type AngleKind int
+
+const (
+	// Acute means the angle is less than 90 degrees.
+	Acute AngleKind = iota
+	// Right means the angle is exactly 90 degrees.
+	Right
+	// Obtuse means the angle is more than 90 degrees.
+	Obtuse
+)
+
+func (k AngleKind) String() string {
+	switch k {
+	case Acute:
+		return "Acute"
+	case Right:
+		return "Right"
+	case Obtuse:
+		return "Obtuse"
+	}
+	return "AngleKind(?)"
+}
+
+// DotSign2d returns the sign of the dot product (a-c)·(b-c) as -1, 0 or 1:
+// positive if the angle acb is acute, zero if it is right or c coincides
+// with a or b, negative if it is obtuse. It is Incircle2pFast negated, with
+// the product first evaluated in floating point and then, if its error
+// bound does not settle the sign, exactly as an expansion.
+func DotSign2d(a, b, c [2]Float) int {
+	return dotSign(a[:], b[:], c[:])
+}
+
+// DotSign3d is DotSign2d in three dimensions.
+func DotSign3d(a, b, c [3]Float) int {
+	return dotSign(a[:], b[:], c[:])
+}
+
+// AngleKind2d classifies the angle acb, at c. If c coincides with a or b
+// the angle is undefined and reported as Right.
+func AngleKind2d(a, b, c [2]Float) AngleKind {
+	return angleKind(DotSign2d(a, b, c))
+}
+
+// AngleKind3d is AngleKind2d in three dimensions.
+func AngleKind3d(a, b, c [3]Float) AngleKind {
+	return angleKind(DotSign3d(a, b, c))
+}
+
+func angleKind(sign int) AngleKind {
+	switch {
+	case sign > 0:
+		return Acute
+	case sign < 0:
+		return Obtuse
+	}
+	return Right
+}
+
+func dotSign(a, b, c []Float) int {
+	det, errbound := dotFast(a, b, c)
+	if det > errbound {
+		return 1
+	}
+	if -det > errbound {
+		return -1
+	}
+	return expansionSign(dotExact(a, b, c))
+}
+
+// dotFast returns (a-c)·(b-c) in floating point and a bound on its error.
+func dotFast(a, b, c []Float) (det, errbound Float) {
+	var detsum Float
+	for k := range a {
+		t := (a[k] - c[k]) * (b[k] - c[k])
+		det += t
+		detsum += abs(t)
+	}
+	return det, doterrboundA * detsum
+}
+
+// dotExact returns (a-c)·(b-c) as an expansion.
+func dotExact(a, b, c []Float) []Float {
+	var sum []Float
+	for k := range a {
+		a1, a0 := twoDiff(a[k], c[k])
+		b1, b0 := twoDiff(b[k], c[k])
+		sum = sumExpansions(sum, product(a1, a0, b1, b0), nil)
+	}
+	return sum
+}
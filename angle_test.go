@@ -0,0 +1,65 @@
+package predicates
+
+import (
+	"math/big"
+	"testing"
+)
+
+func ratDot(a, b, c []Float) int {
+	s := new(big.Rat)
+	for k := range a {
+		ck := new(big.Rat).SetFloat64(float64(c[k]))
+		u := new(big.Rat).Sub(new(big.Rat).SetFloat64(float64(a[k])), ck)
+		v := new(big.Rat).Sub(new(big.Rat).SetFloat64(float64(b[k])), ck)
+		s.Add(s, u.Mul(u, v))
+	}
+	return s.Sign()
+}
+
+func TestAngleKind(t *testing.T) {
+	tests := []struct {
+		a, b, c [2]Float
+		want    AngleKind
+	}{
+		{[2]Float{1, 0}, [2]Float{0, 1}, [2]Float{0, 0}, Right},
+		{[2]Float{1, 0}, [2]Float{1, 1}, [2]Float{0, 0}, Acute},
+		{[2]Float{1, 0}, [2]Float{-1, 1}, [2]Float{0, 0}, Obtuse},
+		{[2]Float{1, 0}, [2]Float{-1, 0}, [2]Float{0, 0}, Obtuse},
+		{[2]Float{1, 0}, [2]Float{5, 0}, [2]Float{0, 0}, Acute},
+		{[2]Float{0, 0}, [2]Float{5, 0}, [2]Float{0, 0}, Right},
+		{[2]Float{3, 1}, [2]Float{0.5, 8.5}, [2]Float{0.5, 0.5}, Acute},
+	}
+	for _, tt := range tests {
+		if got := AngleKind2d(tt.a, tt.b, tt.c); got != tt.want {
+			t.Errorf("AngleKind2d(%v, %v, %v) = %v, want %v", tt.a, tt.b, tt.c, got, tt.want)
+		}
+		a, b, c := [3]Float{tt.a[0], 7, tt.a[1]}, [3]Float{tt.b[0], 7, tt.b[1]}, [3]Float{tt.c[0], 7, tt.c[1]}
+		if got := AngleKind3d(a, b, c); got != tt.want {
+			t.Errorf("AngleKind3d(%v, %v, %v) = %v, want %v", a, b, c, got, tt.want)
+		}
+	}
+}
+
+func TestDotSignRand(t *testing.T) {
+	for i := 0; i < 20000; i++ {
+		var a, b, c [3]Float
+		for k := 0; k < 3; k++ {
+			a[k], b[k], c[k] = narrowRealRand(), narrowRealRand(), narrowRealRand()
+		}
+		if i%2 == 0 {
+			// b-c is a-c turned by a right angle, up to rounding
+			b = [3]Float{c[0] - (a[1] - c[1]), c[1] + (a[0] - c[0]), c[2]}
+		}
+		if got, want := DotSign3d(a, b, c), ratDot(a[:], b[:], c[:]); got != want {
+			t.Errorf("DotSign3d(%v, %v, %v) = %d, want %d", a, b, c, got, want)
+		}
+		a2, b2, c2 := [2]Float{a[0], a[1]}, [2]Float{b[0], b[1]}, [2]Float{c[0], c[1]}
+		want := ratDot(a2[:], b2[:], c2[:])
+		if got := DotSign2d(a2, b2, c2); got != want {
+			t.Errorf("DotSign2d(%v, %v, %v) = %d, want %d", a2, b2, c2, got, want)
+		}
+		if got := Incircle2p(a2, b2, c2); !isSamePred(got, Float(-want)) {
+			t.Errorf("Incircle2p(%v, %v, %v) = %v, want sign %d", a2, b2, c2, got, -want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package predicates
+
+// Orient2dExpansion returns the exact value of the Orient2d determinant,
+// twice the signed area of the triangle pa, pb, pc, as a nonoverlapping
+// expansion, least significant component first. The last component is what
+// Orient2dExact returns.
+func Orient2dExpansion(pa, pb, pc [2]Float) []Float {
+	var w [12]Float
+	n := orient2dExact(pa, pb, pc, &w)
+	return append([]Float(nil), w[:n]...)
+}
+
+// Orient3dExpansion returns the exact value of the Orient3d determinant, six
+// times the signed volume of the tetrahedron pa, pb, pc, pd, as a
+// nonoverlapping expansion, least significant component first. The last
+// component is what Orient3dExact returns.
+func Orient3dExpansion(pa, pb, pc, pd [3]Float) []Float {
+	var deter [96]Float
+	n := orient3dExact(pa, pb, pc, pd, &deter)
+	return append([]Float(nil), deter[:n]...)
+}
+
+// MeshVolume6 returns six times the signed volume enclosed by a closed
+// triangle mesh as a nonoverlapping expansion, least significant component
+// first. Triangles index into vertices and must be counterclockwise when
+// seen from outside for the volume to be positive. The volume is the exact
+// sum of Orient3d(a, b, c, origin) over all triangles, accumulated with
+// FastExpansionSumZeroElim; for a mesh that is not closed the result depends
+// on the position of the origin.
+func MeshVolume6(vertices [][3]Float, triangles [][3]int) []Float {
+	var deter [96]Float
+	var origin [3]Float
+	sum := make([]Float, 0, 96)
+	tmp := make([]Float, 0, 96)
+	for _, t := range triangles {
+		n := orient3dExact(vertices[t[0]], vertices[t[1]], vertices[t[2]], origin, &deter)
+		tmp = sumExpansions(sum, deter[:n], tmp)
+		sum, tmp = tmp, sum
+	}
+	if len(sum) == 0 {
+		sum = append(sum, 0)
+	}
+	return sum
+}
@@ -0,0 +1,71 @@
+package predicates
+
+import "testing"
+
+func TestOrient2dExpansion(t *testing.T) {
+	for i := 0; i < 10000; i++ {
+		pa := [2]Float{narrowRealRand(), narrowRealRand()}
+		pb := [2]Float{narrowRealRand(), narrowRealRand()}
+		pc := [2]Float{narrowRealRand(), narrowRealRand()}
+		e := Orient2dExpansion(pa, pb, pc)
+		if got, want := e[len(e)-1], Orient2dExact(pa, pb, pc); got != want {
+			t.Fatalf("Orient2dExpansion() top = %v, Orient2dExact() = %v", got, want)
+		}
+	}
+}
+
+func TestOrient3dExpansion(t *testing.T) {
+	for i := 0; i < 10000; i++ {
+		pa := [3]Float{narrowRealRand(), narrowRealRand(), narrowRealRand()}
+		pb := [3]Float{narrowRealRand(), narrowRealRand(), narrowRealRand()}
+		pc := [3]Float{narrowRealRand(), narrowRealRand(), narrowRealRand()}
+		pd := [3]Float{narrowRealRand(), narrowRealRand(), narrowRealRand()}
+		e := Orient3dExpansion(pa, pb, pc, pd)
+		if got, want := e[len(e)-1], Orient3dExact(pa, pb, pc, pd); got != want {
+			t.Fatalf("Orient3dExpansion() top = %v, Orient3dExact() = %v", got, want)
+		}
+	}
+}
+
+func TestPolygonArea2(t *testing.T) {
+	// a unit square far from the origin, the shoelace terms are about 1e12
+	// and cancel to 2 exactly
+	const o = 1 << 20
+	square := [][2]Float{{o, o}, {o + 1, o}, {o + 1, o + 1}, {o, o + 1}}
+	e := PolygonArea2(square)
+	if got := Estimate(len(e), &e[0]); got != 2 {
+		t.Errorf("PolygonArea2() = %v, want 2", got)
+	}
+	e = PolygonArea2(reverse(square))
+	if got := Estimate(len(e), &e[0]); got != -2 {
+		t.Errorf("PolygonArea2() = %v, want -2", got)
+	}
+}
+
+func TestMeshVolume6(t *testing.T) {
+	// a unit cube far from the origin with outward facing triangles
+	const o = 1 << 20
+	var vertices [][3]Float
+	for i := 0; i < 8; i++ {
+		vertices = append(vertices, [3]Float{o + Float(i&1), o + Float(i>>1&1), o + Float(i>>2&1)})
+	}
+	triangles := [][3]int{
+		{0, 2, 1}, {1, 2, 3}, // z = 0
+		{4, 5, 6}, {5, 7, 6}, // z = 1
+		{0, 1, 4}, {1, 5, 4}, // y = 0
+		{2, 6, 3}, {3, 6, 7}, // y = 1
+		{0, 4, 2}, {2, 4, 6}, // x = 0
+		{1, 3, 5}, {3, 7, 5}, // x = 1
+	}
+	e := MeshVolume6(vertices, triangles)
+	if got := Estimate(len(e), &e[0]); got != 6 {
+		t.Errorf("MeshVolume6() = %v, want 6", got)
+	}
+	for i := range triangles {
+		triangles[i][1], triangles[i][2] = triangles[i][2], triangles[i][1]
+	}
+	e = MeshVolume6(vertices, triangles)
+	if got := Estimate(len(e), &e[0]); got != -6 {
+		t.Errorf("MeshVolume6() = %v, want -6", got)
+	}
+}
@@ -0,0 +1,194 @@
+package predicates
+
+// Unexpanded versions of the arithmetic macros of predicates.c, for the
+// routines written directly in Go rather than ported from the C code.
+
+// twoSum is Two_Sum: x + y = a + b exactly, x = fl(a + b).
+func twoSum(a, b Float) (x, y Float) {
+	var bvirt, avirt, bround, around Float
+	x = (Float)(a + b)
+	bvirt = (Float)(x - a)
+	avirt = x - bvirt
+	bround = b - bvirt
+	around = a - avirt
+	y = around + bround
+	return
+}
+
+// twoDiff is Two_Diff: x + y = a - b exactly, x = fl(a - b).
+func twoDiff(a, b Float) (x, y Float) {
+	var bvirt, avirt, bround, around Float
+	x = (Float)(a - b)
+	bvirt = (Float)(a - x)
+	avirt = x + bvirt
+	bround = bvirt - b
+	around = a - avirt
+	y = around + bround
+	return
+}
+
+// split is Split: a = hi + lo, each half fits in half the mantissa.
+func split(a Float) (hi, lo Float) {
+	var c, abig Float
+	c = (Float)(splitter * a)
+	abig = (Float)(c - a)
+	hi = c - abig
+	lo = a - hi
+	return
+}
+
+// twoProduct is Two_Product: x + y = a * b exactly, x = fl(a * b).
+func twoProduct(a, b Float) (x, y Float) {
+	var ahi, alo, bhi, blo Float
+	var err1, err2, err3 Float
+	x = (Float)(a * b)
+	ahi, alo = split(a)
+	bhi, blo = split(b)
+	err1 = x - (ahi * bhi)
+	err2 = err1 - (alo * bhi)
+	err3 = err2 - (ahi * blo)
+	y = (alo * blo) - err3
+	return
+}
+
+// twoTwoDiff is Two_Two_Diff: the expansion (a1 + a0) - (b1 + b0), least
+// significant component first.
+func twoTwoDiff(a1, a0, b1, b0 Float) (x [4]Float) {
+	var _i, _j, _0 Float
+	_i, x[0] = twoDiff(a0, b0)
+	_j, _0 = twoSum(a1, _i)
+	_i, x[1] = twoDiff(_0, b1)
+	x[3], x[2] = twoSum(_j, _i)
+	return
+}
+
+// crossProduct returns the expansion of ax*by - ay*bx.
+func crossProduct(ax, ay, bx, by Float) [4]Float {
+	p1, p0 := twoProduct(ax, by)
+	q1, q0 := twoProduct(ay, bx)
+	return twoTwoDiff(p1, p0, q1, q0)
+}
+
+// square returns the expansion of (a1 + a0)^2, least significant component
+// first, for a1 + a0 an expansion such as the result of twoDiff.
+func square(a1, a0 Float) []Float {
+	var h, tmp [12]Float
+	p1, p0 := twoProduct(a1, a1)
+	q1, q0 := twoProduct(a1, a0)
+	r1, r0 := twoProduct(a0, a0)
+	// doubling is exact
+	sum := sumExpansions([]Float{p0, p1}, []Float{2 * q0, 2 * q1}, tmp[:0])
+	return sumExpansions(sum, []Float{r0, r1}, h[:0])
+}
+
+// product returns the expansion of (a1 + a0) * (b1 + b0), least
+// significant component first.
+func product(a1, a0, b1, b0 Float) []Float {
+	var sum []Float
+	for _, p := range [4][2]Float{{a1, b1}, {a1, b0}, {a0, b1}, {a0, b0}} {
+		x, y := twoProduct(p[0], p[1])
+		sum = sumExpansions(sum, []Float{y, x}, nil)
+	}
+	return sum
+}
+
+// sumExpansions returns the sum of the expansions e and f, without zero
+// components. The result is written to h when it has room for it, which
+// must not overlap e or f.
+//
+// It is Fast_Expansion_Sum_Zeroelim on slices: FastExpansionSumZeroElim
+// reads e[elen] and f[flen] ahead, which the C code allows for but which
+// is out of bounds for slices of exactly the right length.
+func sumExpansions(e, f, h []Float) []Float {
+	if len(e) == 0 {
+		return append(h[:0], f...)
+	}
+	if len(f) == 0 {
+		return append(h[:0], e...)
+	}
+	if cap(h) < len(e)+len(f) {
+		h = make([]Float, 0, len(e)+len(f))
+	}
+	h = h[:0]
+	// next returns the component of smaller magnitude of the two next in e
+	// and f
+	i, j := 0, 0
+	next := func() Float {
+		if j == len(f) || i < len(e) && (f[j] > e[i]) == (f[j] > -e[i]) {
+			i++
+			return e[i-1]
+		}
+		j++
+		return f[j-1]
+	}
+	var hh Float
+	q := next()
+	if i < len(e) && j < len(f) {
+		// Fast_Two_Sum, the second component is not larger than the first
+		now := next()
+		qnew := now + q
+		hh = q - (qnew - now)
+		q = qnew
+		if hh != 0 {
+			h = append(h, hh)
+		}
+	}
+	for i < len(e) || j < len(f) {
+		q, hh = twoSum(q, next())
+		if hh != 0 {
+			h = append(h, hh)
+		}
+	}
+	if q != 0 || len(h) == 0 {
+		h = append(h, q)
+	}
+	return h
+}
+
+// scale returns the expansion e times b.
+func scale(e []Float, b Float) []Float {
+	if len(e) == 0 {
+		return nil
+	}
+	h := make([]Float, 2*len(e))
+	return h[:ScaleExpansionZeroElim(len(e), &e[0], b, &h[0])]
+}
+
+// multiply returns the product of the expansions e and f, compressed.
+func multiply(e, f []Float) []Float {
+	var sum []Float
+	for _, b := range f {
+		sum = sumExpansions(sum, scale(e, b), nil)
+	}
+	if len(sum) == 0 {
+		return nil
+	}
+	return sum[:Compress(len(sum), &sum[0], &sum[0])]
+}
+
+// difference returns the expansion of a - b.
+func difference(a, b Float) []Float {
+	x, y := twoDiff(a, b)
+	return []Float{y, x}
+}
+
+// negate returns the expansion -e, written over e.
+func negate(e []Float) []Float {
+	for i := range e {
+		e[i] = -e[i]
+	}
+	return e
+}
+
+// expansionSign returns the sign of the expansion e as -1, 0 or 1.
+func expansionSign(e []Float) int {
+	for i := len(e) - 1; i >= 0; i-- {
+		if e[i] > 0 {
+			return 1
+		}
+		if e[i] < 0 {
+			return -1
+		}
+	}
+	return 0
+}
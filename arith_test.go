@@ -0,0 +1,44 @@
+package predicates
+
+import (
+	"math/big"
+	"testing"
+)
+
+// ratSum returns the exact value of the expansion e.
+func ratSum(e []Float) *big.Rat {
+	sum := new(big.Rat)
+	for _, x := range e {
+		sum.Add(sum, new(big.Rat).SetFloat64(float64(x)))
+	}
+	return sum
+}
+
+func TestSumExpansions(t *testing.T) {
+	for i := 0; i < 10000; i++ {
+		// products of random pairs, summed into expansions of a few
+		// components, in slices of exactly their length
+		var e, f []Float
+		for k := 0; k < int(random()%4); k++ {
+			x, y := twoProduct(narrowRealRand(), narrowRealRand())
+			e = sumExpansions(e, []Float{y, x}, nil)
+		}
+		for k := 0; k < int(random()%4); k++ {
+			x, y := twoProduct(narrowRealRand(), narrowRealRand())
+			f = sumExpansions(f, []Float{y, x}, nil)
+		}
+		e, f = e[:len(e):len(e)], f[:len(f):len(f)]
+		want := ratSum(e)
+		want.Add(want, ratSum(f))
+
+		h := sumExpansions(e, f, nil)
+		if got := ratSum(h); got.Cmp(want) != 0 {
+			t.Fatalf("sumExpansions(%v, %v) = %v, want %v", e, f, h, want)
+		}
+		for k := 1; k < len(h); k++ {
+			if h[k-1] == 0 || abs(h[k-1]) >= abs(h[k]) {
+				t.Fatalf("sumExpansions(%v, %v) = %v, not increasing without zeros", e, f, h)
+			}
+		}
+	}
+}
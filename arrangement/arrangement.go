@@ -0,0 +1,341 @@
+// Package arrangement computes the planar arrangement of a set of line
+// segments with a Bentley–Ottmann sweep: every point where segments meet,
+// and the pieces the segments are cut into.
+//
+// Input endpoints are Float, but intersection points in general are not
+// representable, so they are kept as exact rationals. All the decisions of
+// the sweep, the order of events, which side of a segment a point is on and
+// the order of segments leaving a point, are exact: Orient2d decides them
+// when only input endpoints are involved, and rational arithmetic when an
+// intersection point is. Degenerate configurations, such as many segments
+// through one point, vertical segments, segments ending on others and
+// overlapping collinear segments, produce each vertex exactly once.
+package arrangement
+
+import (
+	"container/heap"
+	"math/big"
+	"sort"
+	"unsafe"
+
+	"github.com/toy80/predicates"
+)
+
+// Float is the floating point type of the coordinates.
+type Float = predicates.Float
+
+// floatSize is the size of Float, to round to it from big.Rat.
+const floatSize = unsafe.Sizeof(*(*Float)(nil))
+
+// Vertex is a point where segments end or meet, with exact coordinates.
+type Vertex struct {
+	X, Y *big.Rat
+	// Segments are the indices of the input segments that end at or pass
+	// through the vertex, in increasing order.
+	Segments []int
+}
+
+// Point returns the coordinates of v rounded to Float, and whether they are
+// exact.
+func (v *Vertex) Point() ([2]Float, bool) {
+	x, xe := ratFloat(v.X)
+	y, ye := ratFloat(v.Y)
+	return [2]Float{x, y}, xe && ye
+}
+
+// ratFloat returns x rounded to the nearest Float, and whether it is exact.
+func ratFloat(x *big.Rat) (Float, bool) {
+	if floatSize == 4 {
+		f, exact := x.Float32()
+		return Float(f), exact
+	}
+	f, exact := x.Float64()
+	return Float(f), exact
+}
+
+// Edge is a piece of one or more input segments between two consecutive
+// vertices on them.
+type Edge struct {
+	// From and To are vertex indices, From is the lexicographically
+	// smaller vertex.
+	From, To int
+	// Segments are the indices of the input segments that contain the
+	// edge, more than one where collinear segments overlap.
+	Segments []int
+}
+
+// Arrangement is the subdivision of the plane induced by a set of segments.
+type Arrangement struct {
+	// Vertices are all the endpoints and intersection points, in
+	// lexicographic order.
+	Vertices []Vertex
+	// Edges are the pieces of the segments between vertices.
+	Edges []Edge
+}
+
+// Intersections returns the vertices where two or more segments meet,
+// including segments touching at their endpoints.
+func (a *Arrangement) Intersections() []Vertex {
+	var vs []Vertex
+	for _, v := range a.Vertices {
+		if len(v.Segments) > 1 {
+			vs = append(vs, v)
+		}
+	}
+	return vs
+}
+
+// New computes the arrangement of segments. A segment whose endpoints
+// coincide is a single point; it becomes a vertex, but no edge.
+func New(segments [][2][2]Float) *Arrangement {
+	s := newSweep(segments)
+	s.run()
+	return &s.result
+}
+
+// point is an event point of the sweep. Input endpoints keep their Float
+// coordinates for the fast exact predicates.
+type point struct {
+	x, y  big.Rat
+	f     [2]Float
+	float bool
+}
+
+func floatPoint(p [2]Float) *point {
+	q := &point{f: p, float: true}
+	q.x.SetFloat64(float64(p[0]))
+	q.y.SetFloat64(float64(p[1]))
+	return q
+}
+
+// compare orders points lexicographically, by x and then by y.
+func compare(p, q *point) int {
+	if p.float && q.float {
+		switch {
+		case p.f[0] < q.f[0] || p.f[0] == q.f[0] && p.f[1] < q.f[1]:
+			return -1
+		case p.f == q.f:
+			return 0
+		}
+		return 1
+	}
+	if c := p.x.Cmp(&q.x); c != 0 {
+		return c
+	}
+	return p.y.Cmp(&q.y)
+}
+
+// segment is an input segment with its endpoints in lexicographic order.
+type segment struct {
+	a, b *point
+}
+
+// side returns the sign of Orient2d(s.a, s.b, p): positive if p is above
+// the segment, in the order of the sweep, and zero if p is on its line.
+func (s *segment) side(p *point) int {
+	if p.float {
+		o := predicates.Orient2d(s.a.f, s.b.f, p.f)
+		switch {
+		case o > 0:
+			return 1
+		case o < 0:
+			return -1
+		}
+		return 0
+	}
+	var dx, dy, px, py, l, r big.Rat
+	dx.Sub(&s.b.x, &s.a.x)
+	dy.Sub(&s.b.y, &s.a.y)
+	px.Sub(&p.x, &s.a.x)
+	py.Sub(&p.y, &s.a.y)
+	l.Mul(&dx, &py)
+	r.Mul(&dy, &px)
+	return l.Cmp(&r)
+}
+
+// turn returns the sign of the cross product of the directions of s and t,
+// positive if t turns counterclockwise from s.
+func turn(s, t *segment) int {
+	var sx, sy, tx, ty, l, r big.Rat
+	sx.Sub(&s.b.x, &s.a.x)
+	sy.Sub(&s.b.y, &s.a.y)
+	tx.Sub(&t.b.x, &t.a.x)
+	ty.Sub(&t.b.y, &t.a.y)
+	l.Mul(&sx, &ty)
+	r.Mul(&sy, &tx)
+	return l.Cmp(&r)
+}
+
+// intersection returns the point where the lines of s and t cross, which
+// must not be parallel.
+func intersection(s, t *segment) *point {
+	var sx, sy, tx, ty, ux, uy, num, den, k big.Rat
+	sx.Sub(&s.b.x, &s.a.x)
+	sy.Sub(&s.b.y, &s.a.y)
+	tx.Sub(&t.b.x, &t.a.x)
+	ty.Sub(&t.b.y, &t.a.y)
+	ux.Sub(&t.a.x, &s.a.x)
+	uy.Sub(&t.a.y, &s.a.y)
+	// s.a + k (s.b - s.a) with k = cross(u, t) / cross(s, t)
+	num.Mul(&ux, &ty)
+	k.Mul(&uy, &tx)
+	num.Sub(&num, &k)
+	den.Mul(&sx, &ty)
+	k.Mul(&sy, &tx)
+	den.Sub(&den, &k)
+	k.Quo(&num, &den)
+	p := &point{}
+	p.x.Mul(&k, &sx)
+	p.x.Add(&p.x, &s.a.x)
+	p.y.Mul(&k, &sy)
+	p.y.Add(&p.y, &s.a.y)
+	return p
+}
+
+// event is a point of the sweep with the segments that start there.
+type event struct {
+	p      *point
+	starts []int
+}
+
+type queue []*event
+
+func (q queue) Len() int            { return len(q) }
+func (q queue) Less(i, j int) bool  { return compare(q[i].p, q[j].p) < 0 }
+func (q queue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *queue) Push(x interface{}) { *q = append(*q, x.(*event)) }
+func (q *queue) Pop() interface{} {
+	old := *q
+	e := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return e
+}
+
+// sweep is the state of the sweep line.
+type sweep struct {
+	segs   []segment
+	events queue
+	// status holds the segments crossing the sweep line from bottom to top
+	status []int
+	// last is the last vertex found on each segment
+	last   []int
+	edges  map[[2]int]int
+	result Arrangement
+}
+
+func newSweep(segments [][2][2]Float) *sweep {
+	s := &sweep{
+		segs:  make([]segment, len(segments)),
+		last:  make([]int, len(segments)),
+		edges: make(map[[2]int]int),
+	}
+	for i, seg := range segments {
+		a, b := floatPoint(seg[0]), floatPoint(seg[1])
+		if compare(b, a) < 0 {
+			a, b = b, a
+		}
+		s.segs[i] = segment{a, b}
+		s.events = append(s.events, &event{p: a, starts: []int{i}})
+		if compare(a, b) != 0 {
+			s.events = append(s.events, &event{p: b})
+		}
+	}
+	heap.Init(&s.events)
+	return s
+}
+
+func (s *sweep) run() {
+	for s.events.Len() > 0 {
+		e := heap.Pop(&s.events).(*event)
+		for s.events.Len() > 0 && compare(s.events[0].p, e.p) == 0 {
+			e.starts = append(e.starts, heap.Pop(&s.events).(*event).starts...)
+		}
+		s.handle(e)
+	}
+}
+
+// handle processes the event e: it records a vertex with the segments
+// through it and updates the status and the events ahead.
+func (s *sweep) handle(e *event) {
+	p := e.p
+	// the segments containing p are contiguous in the status
+	lo := sort.Search(len(s.status), func(i int) bool { return s.segs[s.status[i]].side(p) <= 0 })
+	hi := lo + sort.Search(len(s.status)-lo, func(i int) bool { return s.segs[s.status[lo+i]].side(p) < 0 })
+	through := append([]int(nil), s.status[lo:hi]...)
+
+	v := len(s.result.Vertices)
+	segs := append(append([]int(nil), through...), e.starts...)
+	sort.Ints(segs)
+	n := 0
+	for i, k := range segs {
+		if i == 0 || k != segs[i-1] {
+			segs[n] = k
+			n++
+		}
+	}
+	s.result.Vertices = append(s.result.Vertices, Vertex{X: &p.x, Y: &p.y, Segments: segs[:n]})
+
+	// the pieces of the segments through p end here, the ones that go on
+	// are put back with the ones starting here, ordered by direction
+	var next []int
+	for _, k := range through {
+		s.addEdge(s.last[k], v, k)
+		if compare(s.segs[k].b, p) != 0 {
+			next = append(next, k)
+		}
+	}
+	for _, k := range e.starts {
+		if compare(s.segs[k].a, s.segs[k].b) != 0 {
+			next = append(next, k)
+		}
+	}
+	for _, k := range next {
+		s.last[k] = v
+	}
+	sort.SliceStable(next, func(i, j int) bool {
+		if t := turn(&s.segs[next[i]], &s.segs[next[j]]); t != 0 {
+			return t > 0
+		}
+		return next[i] < next[j]
+	})
+	s.status = append(s.status[:lo], append(next, s.status[hi:]...)...)
+
+	if len(next) == 0 {
+		if lo > 0 && lo < len(s.status) {
+			s.check(s.status[lo-1], s.status[lo], p)
+		}
+		return
+	}
+	if lo > 0 {
+		s.check(s.status[lo-1], s.status[lo], p)
+	}
+	if up := lo + len(next); up < len(s.status) {
+		s.check(s.status[up-1], s.status[up], p)
+	}
+}
+
+// check schedules the crossing of the segments i and j, if they cross at a
+// point after p.
+func (s *sweep) check(i, j int, p *point) {
+	a, b := &s.segs[i], &s.segs[j]
+	if predicates.SegmentsIntersect(a.a.f, a.b.f, b.a.f, b.b.f) != predicates.Crossing {
+		// touching at an endpoint, which is an event already, or overlapping
+		// along a line, where every endpoint is inside the other segment
+		return
+	}
+	q := intersection(a, b)
+	if compare(q, p) > 0 {
+		heap.Push(&s.events, &event{p: q})
+	}
+}
+
+// addEdge records that segment k runs from vertex u to vertex v.
+func (s *sweep) addEdge(u, v, k int) {
+	key := [2]int{u, v}
+	if i, ok := s.edges[key]; ok {
+		s.result.Edges[i].Segments = append(s.result.Edges[i].Segments, k)
+		return
+	}
+	s.edges[key] = len(s.result.Edges)
+	s.result.Edges = append(s.result.Edges, Edge{From: u, To: v, Segments: []int{k}})
+}
@@ -0,0 +1,183 @@
+package arrangement
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/toy80/predicates"
+)
+
+// contains reports whether the closed segment s contains p, exactly.
+func contains(s *segment, p *point) bool {
+	return compare(s.a, p) <= 0 && compare(p, s.b) <= 0 && s.side(p) == 0
+}
+
+// checkArrangement compares the arrangement of segments with a brute force
+// computation: the vertices must be exactly the endpoints and the pairwise
+// crossings, each once and in order, with the segments containing them, and
+// the edges must cut every segment into pieces between its vertices.
+func checkArrangement(t *testing.T, segments [][2][2]Float) *Arrangement {
+	t.Helper()
+	arr := New(segments)
+	s := newSweep(segments)
+
+	want := make(map[string]*point)
+	key := func(p *point) string { return p.x.RatString() + "," + p.y.RatString() }
+	for i := range s.segs {
+		want[key(s.segs[i].a)] = s.segs[i].a
+		want[key(s.segs[i].b)] = s.segs[i].b
+		for j := 0; j < i; j++ {
+			a, b := &s.segs[i], &s.segs[j]
+			if predicates.SegmentsIntersect(a.a.f, a.b.f, b.a.f, b.b.f) == predicates.Crossing {
+				q := intersection(a, b)
+				want[key(q)] = q
+			}
+		}
+	}
+	if len(arr.Vertices) != len(want) {
+		t.Fatalf("%d vertices, want %d", len(arr.Vertices), len(want))
+	}
+	points := make([]*point, len(arr.Vertices))
+	for i, v := range arr.Vertices {
+		p := &point{}
+		p.x.Set(v.X)
+		p.y.Set(v.Y)
+		points[i] = p
+		if want[key(p)] == nil {
+			t.Fatalf("vertex %s is not an endpoint or a crossing", key(p))
+		}
+		if i > 0 && compare(points[i-1], p) >= 0 {
+			t.Fatalf("vertices %s and %s are out of order", key(points[i-1]), key(p))
+		}
+		var segs []int
+		for k := range s.segs {
+			if contains(&s.segs[k], p) {
+				segs = append(segs, k)
+			}
+		}
+		if !equal(v.Segments, segs) {
+			t.Fatalf("vertex %s has segments %v, want %v", key(p), v.Segments, segs)
+		}
+	}
+
+	// every segment is a chain of edges through its vertices in order
+	pieces := make([][]int, len(segments))
+	for _, e := range arr.Edges {
+		if compare(points[e.From], points[e.To]) >= 0 {
+			t.Fatalf("edge %v runs backwards", e)
+		}
+		for _, k := range e.Segments {
+			pieces[k] = append(pieces[k], e.From)
+		}
+	}
+	for k := range s.segs {
+		var on []int
+		for i, p := range points {
+			if contains(&s.segs[k], p) {
+				on = append(on, i)
+			}
+		}
+		sort.Ints(pieces[k])
+		if !equal(pieces[k], on[:len(on)-1]) {
+			t.Fatalf("segment %d has edges from %v, want %v", k, pieces[k], on[:len(on)-1])
+		}
+	}
+	return arr
+}
+
+func equal(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestArrangement(t *testing.T) {
+	// many segments through the origin, including a vertical one
+	var star [][2][2]Float
+	for _, d := range [][2]Float{{1, 0}, {0, 1}, {1, 1}, {1, -1}, {2, 1}, {1, 2}} {
+		star = append(star, [2][2]Float{{-d[0], -d[1]}, d})
+	}
+	tests := []struct {
+		name          string
+		segments      [][2][2]Float
+		vertices      int
+		edges         int
+		intersections int
+	}{
+		{name: "empty", vertices: 0},
+		{name: "cross", segments: [][2][2]Float{{{0, 0}, {2, 2}}, {{0, 2}, {2, 0}}}, vertices: 5, edges: 4, intersections: 1},
+		{name: "inexact crossing", segments: [][2][2]Float{{{0, 0}, {3, 1}}, {{0, 1}, {1, 0}}}, vertices: 5, edges: 4, intersections: 1},
+		{name: "shared endpoint", segments: [][2][2]Float{{{0, 0}, {1, 1}}, {{1, 1}, {2, 0}}, {{1, 1}, {1, 3}}}, vertices: 4, edges: 3, intersections: 1},
+		{name: "T junction", segments: [][2][2]Float{{{0, 0}, {4, 0}}, {{2, 0}, {2, 3}}, {{2, -3}, {2, 0}}}, vertices: 5, edges: 4, intersections: 1},
+		{name: "overlap", segments: [][2][2]Float{{{0, 0}, {4, 4}}, {{1, 1}, {6, 6}}, {{2, 2}, {3, 3}}}, vertices: 6, edges: 5, intersections: 4},
+		{name: "same segment", segments: [][2][2]Float{{{0, 0}, {1, 2}}, {{1, 2}, {0, 0}}}, vertices: 2, edges: 1, intersections: 2},
+		{name: "vertical", segments: [][2][2]Float{{{1, -1}, {1, 5}}, {{0, 0}, {2, 0}}, {{0, 1}, {2, 3}}, {{1, 2}, {1, 4}}}, vertices: 9, edges: 8, intersections: 3},
+		{name: "point", segments: [][2][2]Float{{{0, 0}, {2, 2}}, {{1, 1}, {1, 1}}, {{3, 3}, {3, 3}}}, vertices: 4, edges: 2, intersections: 1},
+		{name: "star", segments: star, vertices: 13, edges: 12, intersections: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arr := checkArrangement(t, tt.segments)
+			if len(arr.Vertices) != tt.vertices {
+				t.Errorf("%d vertices, want %d", len(arr.Vertices), tt.vertices)
+			}
+			if len(arr.Edges) != tt.edges {
+				t.Errorf("%d edges, want %d", len(arr.Edges), tt.edges)
+			}
+			if n := len(arr.Intersections()); n != tt.intersections {
+				t.Errorf("%d intersections, want %d", n, tt.intersections)
+			}
+		})
+	}
+}
+
+func TestArrangementPoint(t *testing.T) {
+	tests := []struct {
+		segments [][2][2]Float
+		want     [2]Float
+		exact    bool
+	}{
+		{segments: [][2][2]Float{{{0, 0}, {3, 1}}, {{3, 0}, {0, 1}}}, want: [2]Float{1.5, 0.5}, exact: true},
+		{segments: [][2][2]Float{{{0, 0}, {3, 1}}, {{1, 0}, {1, 1}}}, want: [2]Float{1, 1.0 / 3}, exact: false},
+	}
+	for _, tt := range tests {
+		vs := New(tt.segments).Intersections()
+		if len(vs) != 1 {
+			t.Fatalf("%d intersections of %v, want 1", len(vs), tt.segments)
+		}
+		if p, exact := vs[0].Point(); p != tt.want || exact != tt.exact {
+			t.Errorf("Point() = %v, %v, want %v, %v", p, exact, tt.want, tt.exact)
+		}
+	}
+}
+
+func TestArrangementRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		// coarse coordinates give shared endpoints, overlaps, vertical
+		// segments and crossings at shared points
+		segments := make([][2][2]Float, 60)
+		for j := range segments {
+			for k := 0; k < 2; k++ {
+				segments[j][k] = [2]Float{Float(r.Intn(8)), Float(r.Intn(8))}
+			}
+		}
+		checkArrangement(t, segments)
+	}
+	for i := 0; i < 5; i++ {
+		segments := make([][2][2]Float, 100)
+		for j := range segments {
+			for k := 0; k < 2; k++ {
+				segments[j][k] = [2]Float{Float(r.Float64()), Float(r.Float64())}
+			}
+		}
+		checkArrangement(t, segments)
+	}
+}
@@ -0,0 +1,337 @@
+package predicates
+
+import (
+	"math"
+	"math/big"
+)
+
+// Circumcenter2d constructs the center of the circle through a, b and c.
+// With the coordinates relative to a, which are exact as expansions, it is
+//
+//	a + (|b|^2 (cy, -cx) - |c|^2 (by, -bx)) / 2 Orient2d(a, b, c)
+//
+// evaluated exactly, so Point is the true center rounded to the nearest
+// Float and Err bounds its error, as for SegmentIntersection. It returns
+// false if the points are collinear.
+func Circumcenter2d(a, b, c [2]Float) (RationalPoint2, bool) {
+	var r RationalPoint2
+	var w [12]Float
+	o := w[:orient2dExact(a, b, c, &w)]
+	if expansionSign(o) == 0 {
+		return r, false
+	}
+	bx, by := difference(b[0], a[0]), difference(b[1], a[1])
+	cx, cy := difference(c[0], a[0]), difference(c[1], a[1])
+	b2 := sumExpansions(multiply(bx, bx), multiply(by, by), nil)
+	c2 := sumExpansions(multiply(cx, cx), multiply(cy, cy), nil)
+	r.Den = scale(o, 2)
+	ux := sumExpansions(multiply(cy, b2), negate(multiply(by, c2)), nil)
+	uy := sumExpansions(multiply(bx, c2), negate(multiply(cx, b2)), nil)
+	r.Num[0] = sumExpansions(scale(r.Den, a[0]), ux, nil)
+	r.Num[1] = sumExpansions(scale(r.Den, a[1]), uy, nil)
+	if expansionSign(r.Den) < 0 {
+		negate(r.Den)
+		negate(r.Num[0])
+		negate(r.Num[1])
+	}
+	r.Err = round(r.Num[:], r.Den, r.Point[:])
+	return r, true
+}
+
+// Circumcenter3d constructs the center of the sphere through a, b, c and d.
+// With the coordinates relative to a it is
+//
+//	a + (|b|^2 c×d + |c|^2 d×b + |d|^2 b×c) / 2 b·(c×d)
+//
+// evaluated exactly, like Circumcenter2d. It returns false if the points
+// are coplanar.
+func Circumcenter3d(a, b, c, d [3]Float) (RationalPoint3, bool) {
+	var r RationalPoint3
+	n, det := circumsphere(a, b, c, d, 0)
+	if expansionSign(det) == 0 {
+		return r, false
+	}
+	r.Den = scale(det, 2)
+	for k := 0; k < 3; k++ {
+		r.Num[k] = sumExpansions(scale(r.Den, a[k]), n[k], nil)
+	}
+	if expansionSign(r.Den) < 0 {
+		for _, e := range [][]Float{r.Den, r.Num[0], r.Num[1], r.Num[2]} {
+			negate(e)
+		}
+	}
+	r.Err = round(r.Num[:], r.Den, r.Point[:])
+	return r, true
+}
+
+// CircumradiusSq2d returns the squared radius of the circle through a, b
+// and c as the quotient of two exact expansions,
+//
+//	|b-a|^2 |c-b|^2 |a-c|^2 / 4 Orient2d(a, b, c)^2
+//
+// so radii can be compared without rounding, by cross-multiplying. The
+// denominator is zero if the points are collinear and positive otherwise.
+//
+// The numerator has degree six in the coordinates, which would underflow
+// for most inputs with a float32 Float. The differences are scaled by a
+// power of two before they are multiplied, and the quotient is balanced
+// between numerator and denominator, so the result stays exact as long as
+// each difference spans no more than about 45 bits, from its leading bit
+// to its lowest one, as is the case for coordinates of similar magnitudes.
+func CircumradiusSq2d(a, b, c [2]Float) (num, den []Float) {
+	var d [3][2][]Float
+	for i, e := range [3][2][2]Float{{a, b}, {b, c}, {c, a}} {
+		for k := 0; k < 2; k++ {
+			d[i][k] = difference(e[1][k], e[0][k])
+		}
+	}
+	// a numerator of degree six near 2^96
+	s := normalizingExp(16, d[0][0], d[0][1], d[1][0], d[1][1], d[2][0], d[2][1])
+	num = []Float{1}
+	for i := range d {
+		for k := range d[i] {
+			d[i][k] = ldexp(d[i][k], s)
+		}
+		num = multiply(num, sumExpansions(multiply(d[i][0], d[i][0]), multiply(d[i][1], d[i][1]), nil))
+	}
+	// (b-a)×(c-a), up to its sign
+	o := sumExpansions(multiply(d[0][0], d[2][1]), negate(multiply(d[0][1], d[2][0])), nil)
+	return balance(num, scale(multiply(o, o), 4), -2*s)
+}
+
+// TriangleCircumradiusSq3d is CircumradiusSq2d for a triangle in space:
+// it returns the squared radius of the circle through a, b and c as
+//
+//	|b-a|^2 |c-b|^2 |a-c|^2 / 4 |(b-a)×(c-a)|^2
+//
+// with a zero denominator if the points are collinear, and exact within
+// the same limits.
+func TriangleCircumradiusSq3d(a, b, c [3]Float) (num, den []Float) {
+	var d [3][3][]Float
+	var all [][]Float
+	for i, e := range [3][2][3]Float{{a, b}, {b, c}, {c, a}} {
+		for k := 0; k < 3; k++ {
+			d[i][k] = difference(e[1][k], e[0][k])
+			all = append(all, d[i][k])
+		}
+	}
+	s := normalizingExp(16, all...)
+	num = []Float{1}
+	for i := range d {
+		for k := range d[i] {
+			d[i][k] = ldexp(d[i][k], s)
+		}
+		num = multiply(num, dot(d[i], d[i]))
+	}
+	// (b-a)×(a-c), the opposite of the normal
+	n := cross(d[0], d[2])
+	return balance(num, scale(dot(n, n), 4), -2*s)
+}
+
+// CircumradiusSq3d returns the squared radius of the sphere through a, b,
+// c and d as the quotient of two exact expansions, the squared length of
+// the numerator of Circumcenter3d over the square of its denominator. The
+// denominator is zero if the points are coplanar. It is scaled like
+// CircumradiusSq2d, but the numerator has degree eight in the coordinates,
+// so with a float32 Float it is only exact while each difference spans no
+// more than about 32 bits.
+func CircumradiusSq3d(a, b, c, d [3]Float) (num, den []Float) {
+	var all [][]Float
+	for _, p := range [3][3]Float{b, c, d} {
+		for k := 0; k < 3; k++ {
+			all = append(all, difference(p[k], a[k]))
+		}
+	}
+	// a numerator of degree eight near 2^96
+	s := normalizingExp(12, all...)
+	n, det := circumsphere(a, b, c, d, s)
+	return balance(dot(n, n), scale(multiply(det, det), 4), -2*s)
+}
+
+// CompareTriangleCircumradius3d compares the radius of the circle through
+// a, b and c with r: it returns -1, 0 or +1 as the radius is smaller than,
+// equal to or larger than |r|. Collinear points have an infinite radius.
+// The squared lengths of the edges and the cross product of
+// TriangleCircumradiusSq3d are exact expansions of low degree, multiplied
+// out with big.Rat, so unlike the quotient it returns the comparison is
+// exact whatever the range of the coordinates.
+func CompareTriangleCircumradius3d(a, b, c [3]Float, r Float) int {
+	var d [3][3][]Float
+	for i, e := range [3][2][3]Float{{a, b}, {b, c}, {c, a}} {
+		for k := 0; k < 3; k++ {
+			d[i][k] = difference(e[1][k], e[0][k])
+		}
+	}
+	num := big.NewRat(1, 1)
+	for i := range d {
+		num.Mul(num, expansionRat(dot(d[i], d[i])))
+	}
+	return compareRadius(num, ratNormSq(cross(d[0], d[2])), 0, r)
+}
+
+// CompareCircumradius3d is CompareTriangleCircumradius3d for the sphere
+// through a, b, c and d, which has an infinite radius if the points are
+// coplanar. The numerator and denominator of the center of Circumcenter3d,
+// of degree four and three, are exact expansions of the differences scaled
+// by a power of two, which keeps them exact for differences spanning up to
+// about 60 bits with a float32 Float.
+func CompareCircumradius3d(a, b, c, d [3]Float, r Float) int {
+	var all [][]Float
+	for _, p := range [3][3]Float{b, c, d} {
+		for k := 0; k < 3; k++ {
+			all = append(all, difference(p[k], a[k]))
+		}
+	}
+	// a numerator of degree four near 2^96
+	s := normalizingExp(24, all...)
+	n, det := circumsphere(a, b, c, d, s)
+	den := expansionRat(det)
+	return compareRadius(ratNormSq(n), den.Mul(den, den), s, r)
+}
+
+// compareRadius compares the squared radius num / 4den of points scaled by
+// 2^s with r^2, as -1, 0 or +1; a zero den is an infinite radius.
+func compareRadius(num, den *big.Rat, s int, r Float) int {
+	if den.Sign() == 0 {
+		return 1
+	}
+	scaled := new(big.Rat).SetFloat64(float64(r))
+	if s >= 0 {
+		scaled.Mul(scaled, new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), uint(s))))
+	} else {
+		scaled.Quo(scaled, new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), uint(-s))))
+	}
+	rhs := new(big.Rat).Mul(scaled, scaled)
+	rhs.Mul(rhs, den)
+	rhs.Mul(rhs, big.NewRat(4, 1))
+	return num.Cmp(rhs)
+}
+
+// ratNormSq returns the squared length of the vector of expansions v.
+func ratNormSq(v [3][]Float) *big.Rat {
+	sum := new(big.Rat)
+	for _, e := range v {
+		x := expansionRat(e)
+		sum.Add(sum, x.Mul(x, x))
+	}
+	return sum
+}
+
+// normalizingExp returns the power of two by which to scale the
+// expansions e to bring the largest of them near 2^top, which leaves room
+// in the range of Float for products of several of them.
+func normalizingExp(top int, e ...[]Float) int {
+	largest, ok := 0, false
+	for _, x := range e {
+		if expansionSign(x) == 0 {
+			continue
+		}
+		if k := exponent(x[len(x)-1]); !ok || k > largest {
+			largest, ok = k, true
+		}
+	}
+	if !ok {
+		return 0
+	}
+	return top - largest
+}
+
+// balance returns num 2^p and den 2^q, with p - q = k, so that their
+// quotient is num/den 2^k. It picks p in the middle of the range that
+// keeps the components of both normal numbers, where scaling is exact, or
+// if there is none the largest p for which neither overflows.
+func balance(num, den []Float, k int) ([]Float, []Float) {
+	nlo, nhi := exponentRoom(num)
+	dlo, dhi := exponentRoom(den)
+	lo, hi := nlo, nhi
+	if dlo+k > lo {
+		lo = dlo + k
+	}
+	if dhi+k < hi {
+		hi = dhi + k
+	}
+	p := lo + (hi-lo)/2
+	if lo > hi {
+		p = hi
+	}
+	return ldexp(num, p), ldexp(den, p-k)
+}
+
+// exponentRoom returns the smallest and largest powers of two by which the
+// expansion e can be scaled with its components staying normal numbers.
+func exponentRoom(e []Float) (lo, hi int) {
+	minExp, maxExp := -1021, 1024
+	if floatSize == 4 {
+		minExp, maxExp = -125, 128
+	}
+	lo, hi = math.MinInt32, math.MaxInt32
+	for _, x := range e {
+		if x == 0 {
+			continue
+		}
+		k := exponent(x)
+		if minExp-k > lo {
+			lo = minExp - k
+		}
+		if maxExp-k < hi {
+			hi = maxExp - k
+		}
+	}
+	return lo, hi
+}
+
+// exponent returns the binary exponent of x as math.Frexp does, so that
+// |x| is in [2^(k-1), 2^k).
+func exponent(x Float) int {
+	_, k := math.Frexp(float64(x))
+	return k
+}
+
+// ldexp returns the expansion e times 2^k, which is exact as long as its
+// components stay within the range of Float.
+func ldexp(e []Float, k int) []Float {
+	h := make([]Float, len(e))
+	for i, x := range e {
+		h[i] = Float(math.Ldexp(float64(x), k))
+	}
+	return h
+}
+
+// circumsphere returns n = |b|^2 c×d + |c|^2 d×b + |d|^2 b×c and
+// det = b·(c×d), with b, c and d taken relative to a and scaled by 2^s, so
+// that the center of the sphere through the four points is a + n / 2^(s+1)
+// det.
+func circumsphere(a, b, c, d [3]Float, s int) (n [3][]Float, det []Float) {
+	var u, v, w [3][]Float
+	for k := 0; k < 3; k++ {
+		u[k] = ldexp(difference(b[k], a[k]), s)
+		v[k] = ldexp(difference(c[k], a[k]), s)
+		w[k] = ldexp(difference(d[k], a[k]), s)
+	}
+	vw, wu, uv := cross(v, w), cross(w, u), cross(u, v)
+	u2, v2, w2 := dot(u, u), dot(v, v), dot(w, w)
+	for k := 0; k < 3; k++ {
+		n[k] = sumExpansions(multiply(u2, vw[k]), multiply(v2, wu[k]), nil)
+		n[k] = sumExpansions(n[k], multiply(w2, uv[k]), nil)
+	}
+	return n, dot(u, vw)
+}
+
+// cross returns the cross product of two vectors of expansions.
+func cross(u, v [3][]Float) [3][]Float {
+	var c [3][]Float
+	for k := 0; k < 3; k++ {
+		i, j := (k+1)%3, (k+2)%3
+		c[k] = sumExpansions(multiply(u[i], v[j]), negate(multiply(u[j], v[i])), nil)
+	}
+	return c
+}
+
+// dot returns the dot product of two vectors of expansions.
+func dot(u, v [3][]Float) []Float {
+	var s []Float
+	for k := 0; k < 3; k++ {
+		s = sumExpansions(s, multiply(u[k], v[k]), nil)
+	}
+	return s
+}
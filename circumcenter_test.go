@@ -0,0 +1,307 @@
+package predicates
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestCircumcenter2d(t *testing.T) {
+	tests := []struct {
+		a, b, c [2]Float
+		want    [2]Float
+		ok      bool
+	}{
+		{[2]Float{0, 0}, [2]Float{2, 0}, [2]Float{0, 2}, [2]Float{1, 1}, true},
+		{[2]Float{0, 0}, [2]Float{0, 2}, [2]Float{2, 0}, [2]Float{1, 1}, true},
+		{[2]Float{-1, 0}, [2]Float{1, 0}, [2]Float{0, 1}, [2]Float{0, 0}, true},
+		{[2]Float{0, 0}, [2]Float{1, 1}, [2]Float{3, 3}, [2]Float{}, false},
+		{[2]Float{1, 1}, [2]Float{1, 1}, [2]Float{3, 0}, [2]Float{}, false},
+	}
+	for _, tt := range tests {
+		r, ok := Circumcenter2d(tt.a, tt.b, tt.c)
+		if ok != tt.ok || ok && (r.Point != tt.want || r.Err != 0) {
+			t.Errorf("Circumcenter2d(%v, %v, %v) = %v, %v, %v, want %v, %v", tt.a, tt.b, tt.c, r.Point, r.Err, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCircumcenter3d(t *testing.T) {
+	r, ok := Circumcenter3d([3]Float{0, 0, 0}, [3]Float{2, 0, 0}, [3]Float{0, 2, 0}, [3]Float{0, 0, 2})
+	if want := [3]Float{1, 1, 1}; !ok || r.Point != want || r.Err != 0 {
+		t.Errorf("Circumcenter3d = %v, %v, %v, want %v", r.Point, r.Err, ok, want)
+	}
+	if _, ok := Circumcenter3d([3]Float{0, 0, 0}, [3]Float{2, 0, 0}, [3]Float{0, 2, 0}, [3]Float{5, 7, 0}); ok {
+		t.Errorf("Circumcenter3d of coplanar points = true")
+	}
+}
+
+// ratSq returns |p-q|^2 exactly.
+func ratSq(p, q []*big.Rat) *big.Rat {
+	s := new(big.Rat)
+	for k := range p {
+		d := new(big.Rat).Sub(p[k], q[k])
+		s.Add(s, d.Mul(d, d))
+	}
+	return s
+}
+
+func ratCoords(p []Float) []*big.Rat {
+	c := make([]*big.Rat, len(p))
+	for k, x := range p {
+		c[k] = new(big.Rat).SetFloat64(float64(x))
+	}
+	return c
+}
+
+func TestCircumcenter2dRand(t *testing.T) {
+	for i := 0; i < 10000; i++ {
+		var a, b, c [2]Float
+		for k := 0; k < 2; k++ {
+			a[k], b[k], c[k] = smallRand(), smallRand(), smallRand()
+		}
+		if i%2 == 0 {
+			// a nearly degenerate triangle, with a center far away
+			for k := 0; k < 2; k++ {
+				c[k] = a[k] + (b[k]-a[k])*1.5
+			}
+		}
+		r, ok := Circumcenter2d(a, b, c)
+		if ok != (Orient2d(a, b, c) != 0) {
+			t.Fatalf("Circumcenter2d(%v, %v, %v) = %v, disagrees with Orient2d", a, b, c, ok)
+		}
+		if !ok {
+			continue
+		}
+		// the exact center is equidistant from the three points
+		d := expansionRat(r.Den)
+		center := []*big.Rat{new(big.Rat).Quo(expansionRat(r.Num[0]), d), new(big.Rat).Quo(expansionRat(r.Num[1]), d)}
+		ra := ratSq(center, ratCoords(a[:]))
+		if ra.Cmp(ratSq(center, ratCoords(b[:]))) != 0 || ra.Cmp(ratSq(center, ratCoords(c[:]))) != 0 {
+			t.Fatalf("Circumcenter2d(%v, %v, %v) is not equidistant", a, b, c)
+		}
+		checkRational(t, r.Num[:], r.Den, r.Point[:], r.Err, center)
+		num, den := CircumradiusSq2d(a, b, c)
+		if got := new(big.Rat).Quo(expansionRat(num), expansionRat(den)); got.Cmp(ra) != 0 {
+			t.Fatalf("CircumradiusSq2d(%v, %v, %v) = %v, want %v", a, b, c, got, ra)
+		}
+	}
+}
+
+// ratCircumradiusSq returns the squared radius of the circle through the
+// points a, b and c, in two or three dimensions, computed with big.Rat.
+func ratCircumradiusSq(a, b, c []*big.Rat) *big.Rat {
+	u, v := make([]*big.Rat, len(a)), make([]*big.Rat, len(a))
+	for k := range a {
+		u[k], v[k] = new(big.Rat).Sub(b[k], a[k]), new(big.Rat).Sub(c[k], a[k])
+	}
+	// the cross product, or its only component in two dimensions
+	axes := [][2]int{{1, 2}, {2, 0}, {0, 1}}
+	if len(a) == 2 {
+		axes = axes[2:]
+	}
+	var normal, zero []*big.Rat
+	for _, ax := range axes {
+		x := new(big.Rat).Mul(u[ax[0]], v[ax[1]])
+		normal = append(normal, x.Sub(x, new(big.Rat).Mul(u[ax[1]], v[ax[0]])))
+		zero = append(zero, new(big.Rat))
+	}
+	num := ratSq(a, b)
+	num.Mul(num, ratSq(b, c))
+	num.Mul(num, ratSq(c, a))
+	den := ratSq(normal, zero)
+	return num.Quo(num, den.Mul(den, big.NewRat(4, 1)))
+}
+
+func TestCircumradiusSqUniform(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, scale := range []Float{1, 1e-2, 1e3} {
+		for i := 0; i < 2000; i++ {
+			var a, b, c [3]Float
+			for k := 0; k < 3; k++ {
+				a[k], b[k], c[k] = Float(r.Float64())*scale, Float(r.Float64())*scale, Float(r.Float64())*scale
+			}
+			want := ratCircumradiusSq(ratCoords(a[:2]), ratCoords(b[:2]), ratCoords(c[:2]))
+			num, den := CircumradiusSq2d([2]Float{a[0], a[1]}, [2]Float{b[0], b[1]}, [2]Float{c[0], c[1]})
+			if got := new(big.Rat).Quo(expansionRat(num), expansionRat(den)); got.Cmp(want) != 0 {
+				t.Fatalf("CircumradiusSq2d(%v, %v, %v) = %v, want %v", a[:2], b[:2], c[:2], got, want)
+			}
+			want = ratCircumradiusSq(ratCoords(a[:]), ratCoords(b[:]), ratCoords(c[:]))
+			num, den = TriangleCircumradiusSq3d(a, b, c)
+			if got := new(big.Rat).Quo(expansionRat(num), expansionRat(den)); got.Cmp(want) != 0 {
+				t.Fatalf("TriangleCircumradiusSq3d(%v, %v, %v) = %v, want %v", a, b, c, got, want)
+			}
+		}
+	}
+}
+
+func TestCompareCircumradius(t *testing.T) {
+	if got := CompareTriangleCircumradius3d([3]Float{-1, 0, 0}, [3]Float{1, 0, 0}, [3]Float{0, 1, 0}, 1); got != 0 {
+		t.Errorf("CompareTriangleCircumradius3d(unit circle, 1) = %d, want 0", got)
+	}
+	if got := CompareCircumradius3d([3]Float{1, 0, 0}, [3]Float{-1, 0, 0}, [3]Float{0, 1, 0}, [3]Float{0, 0, 1}, -1); got != 0 {
+		t.Errorf("CompareCircumradius3d(unit sphere, -1) = %d, want 0", got)
+	}
+	if got := CompareTriangleCircumradius3d([3]Float{0, 0, 0}, [3]Float{1, 2, 3}, [3]Float{2, 4, 6}, 1e30); got != 1 {
+		t.Errorf("CompareTriangleCircumradius3d(collinear) = %d, want 1", got)
+	}
+	if got := CompareCircumradius3d([3]Float{0, 0, 0}, [3]Float{1, 0, 0}, [3]Float{0, 1, 0}, [3]Float{5, 7, 0}, 1e30); got != 1 {
+		t.Errorf("CompareCircumradius3d(coplanar) = %d, want 1", got)
+	}
+
+	rnd := rand.New(rand.NewSource(2))
+	for _, scale := range []Float{1, 1e-2, 1e3} {
+		for i := 0; i < 1000; i++ {
+			var a, b, c, d [3]Float
+			for k := 0; k < 3; k++ {
+				a[k], b[k] = Float(rnd.Float64())*scale, Float(rnd.Float64())*scale
+				c[k], d[k] = Float(rnd.Float64())*scale, Float(rnd.Float64())*scale
+			}
+			// the exact squared radius of the sphere, from Circumcenter3d
+			// computed with big.Rat
+			ra, rb, rc, rd := ratCoords(a[:]), ratCoords(b[:]), ratCoords(c[:]), ratCoords(d[:])
+			var m [3][]*big.Rat
+			rhs := make([]*big.Rat, 3)
+			for i, p := range [][]*big.Rat{rb, rc, rd} {
+				m[i] = make([]*big.Rat, 3)
+				for k := range m[i] {
+					m[i][k] = new(big.Rat).Sub(p[k], ra[k])
+				}
+				rhs[i] = new(big.Rat).Mul(ratSq(p, ra), big.NewRat(1, 2))
+			}
+			center := solve3(m, rhs)
+			sphere := ratSq(center, make3(new(big.Rat)))
+			for _, tt := range []struct {
+				name string
+				want *big.Rat
+				got  func(Float) int
+			}{
+				{"CompareTriangleCircumradius3d", ratCircumradiusSq(ra, rb, rc), func(r Float) int { return CompareTriangleCircumradius3d(a, b, c, r) }},
+				{"CompareCircumradius3d", sphere, func(r Float) int { return CompareCircumradius3d(a, b, c, d, r) }},
+			} {
+				f, _ := tt.want.Float64()
+				r := Float(math.Sqrt(f))
+				for _, r := range []Float{r, nextUp(r), -nextUp(-r)} {
+					rr := new(big.Rat).SetFloat64(float64(r))
+					if got, want := tt.got(r), tt.want.Cmp(rr.Mul(rr, rr)); got != want {
+						t.Fatalf("%s(%v, %v, %v, %v, %v) = %d, want %d", tt.name, a, b, c, d, r, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+// make3 returns the vector (x, x, x).
+func make3(x *big.Rat) []*big.Rat {
+	return []*big.Rat{x, x, x}
+}
+
+// solve3 solves the linear system m x = rhs by Cramer's rule.
+func solve3(m [3][]*big.Rat, rhs []*big.Rat) []*big.Rat {
+	det := func(m [3][]*big.Rat) *big.Rat {
+		s := new(big.Rat)
+		for k := 0; k < 3; k++ {
+			i, j := (k+1)%3, (k+2)%3
+			x := new(big.Rat).Mul(m[1][i], m[2][j])
+			x.Sub(x, new(big.Rat).Mul(m[1][j], m[2][i]))
+			s.Add(s, x.Mul(x, m[0][k]))
+		}
+		return s
+	}
+	d := det(m)
+	x := make([]*big.Rat, 3)
+	for k := 0; k < 3; k++ {
+		var mk [3][]*big.Rat
+		for i := range mk {
+			mk[i] = append([]*big.Rat(nil), m[i]...)
+			mk[i][k] = rhs[i]
+		}
+		x[k] = new(big.Rat).Quo(det(mk), d)
+	}
+	return x
+}
+
+// gridRand returns a coordinate on a grid of spacing 2^-8, coarse enough
+// for the expansions of CircumradiusSq3d not to underflow in float32.
+func gridRand() Float {
+	return Float(random()%(1<<20)) / (1 << 8)
+}
+
+func TestCircumcenter3dRand(t *testing.T) {
+	for i := 0; i < 5000; i++ {
+		var a, b, c, d [3]Float
+		for k := 0; k < 3; k++ {
+			a[k], b[k], c[k], d[k] = gridRand(), gridRand(), gridRand(), gridRand()
+		}
+		if i%2 == 0 {
+			// a nearly flat tetrahedron
+			for k := 0; k < 3; k++ {
+				d[k] = a[k] + (b[k]-a[k])*0.75 + (c[k]-a[k])*0.5
+			}
+		}
+		r, ok := Circumcenter3d(a, b, c, d)
+		if ok != (Orient3d(a, b, c, d) != 0) {
+			t.Fatalf("Circumcenter3d(%v, %v, %v, %v) = %v, disagrees with Orient3d", a, b, c, d, ok)
+		}
+		if !ok {
+			continue
+		}
+		den := expansionRat(r.Den)
+		center := make([]*big.Rat, 3)
+		for k := range center {
+			center[k] = new(big.Rat).Quo(expansionRat(r.Num[k]), den)
+		}
+		ra := ratSq(center, ratCoords(a[:]))
+		for _, p := range [][3]Float{b, c, d} {
+			if ra.Cmp(ratSq(center, ratCoords(p[:]))) != 0 {
+				t.Fatalf("Circumcenter3d(%v, %v, %v, %v) is not equidistant", a, b, c, d)
+			}
+		}
+		checkRational(t, r.Num[:], r.Den, r.Point[:], r.Err, center)
+		num, dd := CircumradiusSq3d(a, b, c, d)
+		if got := new(big.Rat).Quo(expansionRat(num), expansionRat(dd)); got.Cmp(ra) != 0 {
+			t.Fatalf("CircumradiusSq3d(%v, %v, %v, %v) = %v, want %v", a, b, c, d, got, ra)
+		}
+	}
+}
+
+func TestTriangleCircumradiusSq3d(t *testing.T) {
+	for i := 0; i < 5000; i++ {
+		var a, b, c [3]Float
+		for k := 0; k < 3; k++ {
+			a[k], b[k], c[k] = smallRand(), smallRand(), smallRand()
+		}
+		// the circumradius is that of the triangle in its own plane, the
+		// smallest over the spheres through the three points; compare with
+		// the plane of the triangle rotated onto z = 0 when it already lies
+		// there
+		if i%2 == 0 {
+			a[2], b[2], c[2] = 0, 0, 0
+			num, den := TriangleCircumradiusSq3d(a, b, c)
+			n2, d2 := CircumradiusSq2d([2]Float{a[0], a[1]}, [2]Float{b[0], b[1]}, [2]Float{c[0], c[1]})
+			got := new(big.Rat).Quo(expansionRat(num), expansionRat(den))
+			if want := new(big.Rat).Quo(expansionRat(n2), expansionRat(d2)); got.Cmp(want) != 0 {
+				t.Fatalf("TriangleCircumradiusSq3d(%v, %v, %v) = %v, want %v", a, b, c, got, want)
+			}
+			continue
+		}
+		// otherwise the sphere through the points and a fourth point on the
+		// normal through the circumcenter has a larger radius
+		num, den := TriangleCircumradiusSq3d(a, b, c)
+		got := new(big.Rat).Quo(expansionRat(num), expansionRat(den))
+		for _, d := range [][3]Float{{1, 2, 3}, {-5, 0.5, 2}} {
+			if Orient3d(a, b, c, d) == 0 {
+				continue
+			}
+			n3, d3 := CircumradiusSq3d(a, b, c, d)
+			if new(big.Rat).Quo(expansionRat(n3), expansionRat(d3)).Cmp(got) < 0 {
+				t.Fatalf("TriangleCircumradiusSq3d(%v, %v, %v) = %v, larger than a sphere through them", a, b, c, got)
+			}
+		}
+	}
+	if _, den := TriangleCircumradiusSq3d([3]Float{0, 0, 0}, [3]Float{1, 2, 3}, [3]Float{2, 4, 6}); expansionSign(den) != 0 {
+		t.Errorf("TriangleCircumradiusSq3d of collinear points has denominator %v", den)
+	}
+}
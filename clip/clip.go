@@ -0,0 +1,335 @@
+// Package clip computes boolean operations on polygons: union, intersection,
+// difference and symmetric difference.
+//
+// A polygon is a set of rings, as for predicates.PointInPolygon, so it can
+// have several parts and holes, and its inside is decided by a fill rule.
+// The rings of both operands are cut against each other by an exact
+// arrangement, and every topological decision, where edges cross, which
+// side of the result an edge is on and how the boundary continues at a
+// vertex, is made with Orient2d or exact rational arithmetic. Only the
+// coordinates of the result are rounded, to the nearest Float.
+package clip
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/toy80/predicates"
+	"github.com/toy80/predicates/arrangement"
+)
+
+// Float is the floating point type of the coordinates.
+type Float = predicates.Float
+
+// Op is a boolean operation on two polygons.
+type Op int
+
+const (
+	// Union keeps the points inside either polygon.
+	Union Op = iota
+	// Intersection keeps the points inside both polygons.
+	Intersection
+	// Difference keeps the points inside the first polygon but not the
+	// second.
+	Difference
+	// Xor keeps the points inside exactly one of the polygons.
+	Xor
+)
+
+func (op Op) keep(a, b bool) bool {
+	switch op {
+	case Union:
+		return a || b
+	case Intersection:
+		return a && b
+	case Difference:
+		return a && !b
+	}
+	return a != b
+}
+
+// Boolean returns the polygon op(a, b), where the inside of a and b is
+// decided by rule. The result is a set of rings with the inside on their
+// left: outer boundaries are counterclockwise and holes clockwise, so it
+// has the same inside under both fill rules. Rings do not cross, but they
+// can touch at vertices; each ring turns at every vertex it has.
+//
+// Vertices where edges cross are rounded to Float, so the result can be
+// off by an ulp where it passes through such a crossing.
+func Boolean(a, b [][][2]Float, op Op, rule predicates.FillRule) [][][2]Float {
+	g := newGraph([2][][][2]Float{a, b})
+	g.windings()
+	return g.rings(func(w [2]int) bool {
+		return op.keep(rule.Inside(w[0]), rule.Inside(w[1]))
+	})
+}
+
+// source is a ring edge of one of the operands.
+type source struct {
+	poly, ring int
+	// forward is true if the ring runs along the edge in lexicographic
+	// order of its endpoints
+	forward bool
+	// dx and dy are the direction of the edge in lexicographic order
+	dx, dy big.Rat
+}
+
+// graph is the arrangement of the rings of both operands. Half-edge 2e runs
+// along arrangement edge e from its From vertex to its To vertex, and 2e+1
+// runs back.
+type graph struct {
+	polys   [2][][][2]Float
+	arr     *arrangement.Arrangement
+	sources []source
+	// out lists the half-edges leaving each vertex counterclockwise
+	out [][]int
+	// pos is the index of each half-edge in out of its tail
+	pos []int
+	// wind is the winding number of both operands on the left of each
+	// half-edge
+	wind [][2]int
+}
+
+func newGraph(polys [2][][][2]Float) *graph {
+	g := &graph{polys: polys}
+	var segments [][2][2]Float
+	for i, poly := range polys {
+		for j, ring := range poly {
+			for k, p := range ring {
+				q := ring[(k+1)%len(ring)]
+				if p == q {
+					continue
+				}
+				s := source{poly: i, ring: j, forward: less(p, q)}
+				if !s.forward {
+					p, q = q, p
+				}
+				s.dx.SetFloat64(float64(q[0]))
+				s.dx.Sub(&s.dx, new(big.Rat).SetFloat64(float64(p[0])))
+				s.dy.SetFloat64(float64(q[1]))
+				s.dy.Sub(&s.dy, new(big.Rat).SetFloat64(float64(p[1])))
+				g.sources = append(g.sources, s)
+				segments = append(segments, [2][2]Float{p, q})
+			}
+		}
+	}
+	g.arr = arrangement.New(segments)
+
+	g.out = make([][]int, len(g.arr.Vertices))
+	for e, edge := range g.arr.Edges {
+		g.out[edge.From] = append(g.out[edge.From], 2*e)
+		g.out[edge.To] = append(g.out[edge.To], 2*e+1)
+	}
+	g.pos = make([]int, 2*len(g.arr.Edges))
+	for _, hs := range g.out {
+		sort.Slice(hs, func(i, j int) bool { return g.ccw(hs[i], hs[j]) })
+		for i, h := range hs {
+			g.pos[h] = i
+		}
+	}
+	return g
+}
+
+func less(a, b [2]Float) bool {
+	return a[0] < b[0] || a[0] == b[0] && a[1] < b[1]
+}
+
+// direction returns the direction of the half-edge h, with the sign of its
+// coordinates flipped if it runs backwards.
+func (g *graph) direction(h int) (x, y *big.Rat, flip bool) {
+	s := &g.sources[g.arr.Edges[h/2].Segments[0]]
+	return &s.dx, &s.dy, h%2 == 1
+}
+
+// cross returns the sign of the cross product of the directions of the
+// half-edges h and k.
+func (g *graph) cross(h, k int) int {
+	hx, hy, hf := g.direction(h)
+	kx, ky, kf := g.direction(k)
+	var l, r big.Rat
+	l.Mul(hx, ky)
+	r.Mul(hy, kx)
+	c := l.Cmp(&r)
+	if hf != kf {
+		c = -c
+	}
+	return c
+}
+
+// upper reports whether the direction of the half-edge h has an angle in
+// [0, 180) degrees.
+func (g *graph) upper(h int) bool {
+	x, y, flip := g.direction(h)
+	sx, sy := x.Sign(), y.Sign()
+	if flip {
+		sx, sy = -sx, -sy
+	}
+	return sy > 0 || sy == 0 && sx > 0
+}
+
+// ccw reports whether the half-edge h comes before k counterclockwise,
+// starting from the direction of the positive x axis.
+func (g *graph) ccw(h, k int) bool {
+	if uh, uk := g.upper(h), g.upper(k); uh != uk {
+		return uh
+	}
+	return g.cross(h, k) > 0
+}
+
+func (g *graph) tail(h int) int {
+	if h%2 == 0 {
+		return g.arr.Edges[h/2].From
+	}
+	return g.arr.Edges[h/2].To
+}
+
+func (g *graph) head(h int) int {
+	return g.tail(h ^ 1)
+}
+
+// next returns the half-edge after h on the boundary of the face on its
+// left: the first one clockwise from the twin of h around its head.
+func (g *graph) next(h int) int {
+	hs := g.out[g.head(h)]
+	return hs[(g.pos[h^1]+len(hs)-1)%len(hs)]
+}
+
+// delta returns the winding numbers on the left of the half-edge h minus
+// those on its right.
+func (g *graph) delta(h int) [2]int {
+	var d [2]int
+	for _, k := range g.arr.Edges[h/2].Segments {
+		s := &g.sources[k]
+		if s.forward == (h%2 == 0) {
+			d[s.poly]++
+		} else {
+			d[s.poly]--
+		}
+	}
+	return d
+}
+
+// windings computes the winding numbers on the left of every half-edge.
+// They spread across edges and around faces from the leftmost vertex of
+// each connected component, an input vertex whose winding numbers with
+// respect to the rings of other components are exact.
+func (g *graph) windings() {
+	g.wind = make([][2]int, len(g.pos))
+	done := make([]bool, len(g.pos))
+	for v, hs := range g.out {
+		if len(hs) == 0 || done[hs[0]] {
+			continue
+		}
+		// the vertices are in lexicographic order, so v is the leftmost of a
+		// new component, and the region left of it is on the left of its
+		// topmost half-edge
+		top := hs[len(hs)-1]
+		for _, h := range hs {
+			if g.upper(h) {
+				top = h
+			}
+		}
+		mine := make(map[[2]int]bool)
+		g.component(top, func(h int) {
+			for _, k := range g.arr.Edges[h/2].Segments {
+				mine[[2]int{g.sources[k].poly, g.sources[k].ring}] = true
+			}
+		})
+		p, _ := g.arr.Vertices[v].Point()
+		var w [2]int
+		for i, poly := range g.polys {
+			for j, ring := range poly {
+				if !mine[[2]int{i, j}] {
+					w[i] += predicates.WindingNumber(ring, p)
+				}
+			}
+		}
+
+		g.wind[top] = w
+		done[top] = true
+		stack := []int{top}
+		for len(stack) > 0 {
+			h := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			d := g.delta(h)
+			twin := [2]int{g.wind[h][0] - d[0], g.wind[h][1] - d[1]}
+			for _, k := range [2]int{g.next(h), h ^ 1} {
+				if done[k] {
+					continue
+				}
+				done[k] = true
+				g.wind[k] = g.wind[h]
+				if k == h^1 {
+					g.wind[k] = twin
+				}
+				stack = append(stack, k)
+			}
+		}
+	}
+}
+
+// component calls f for every half-edge connected to h.
+func (g *graph) component(h int, f func(h int)) {
+	seen := map[int]bool{h: true}
+	stack := []int{h}
+	for len(stack) > 0 {
+		h := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		f(h)
+		for _, k := range append(append([]int{h ^ 1}, g.out[g.tail(h)]...), g.out[g.head(h)]...) {
+			if !seen[k] {
+				seen[k] = true
+				stack = append(stack, k)
+			}
+		}
+	}
+}
+
+// rings traces the boundary of the region where inside holds, with the
+// region on the left.
+func (g *graph) rings(inside func(w [2]int) bool) [][][2]Float {
+	boundary := make([]bool, len(g.pos))
+	for h := range boundary {
+		boundary[h] = inside(g.wind[h]) && !inside(g.wind[h^1])
+	}
+	var rings [][][2]Float
+	for start := range boundary {
+		if !boundary[start] {
+			continue
+		}
+		var hs []int
+		for h := start; boundary[h]; {
+			boundary[h] = false
+			hs = append(hs, h)
+			// turn clockwise from the twin of h to the next boundary edge
+			out := g.out[g.head(h)]
+			i := g.pos[h^1]
+			for {
+				i = (i + len(out) - 1) % len(out)
+				if k := out[i]; boundary[k] || k == start {
+					h = k
+					break
+				}
+			}
+		}
+		var ring [][2]Float
+		for i, h := range hs {
+			prev := hs[(i+len(hs)-1)%len(hs)]
+			if g.cross(prev, h) == 0 && g.upper(prev) == g.upper(h) {
+				// straight on
+				continue
+			}
+			p, _ := g.arr.Vertices[g.tail(h)].Point()
+			if len(ring) == 0 || ring[len(ring)-1] != p {
+				ring = append(ring, p)
+			}
+		}
+		for len(ring) > 1 && ring[0] == ring[len(ring)-1] {
+			ring = ring[:len(ring)-1]
+		}
+		if len(ring) >= 3 {
+			rings = append(rings, ring)
+		}
+	}
+	return rings
+}
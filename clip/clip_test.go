@@ -0,0 +1,128 @@
+package clip
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/toy80/predicates"
+)
+
+func square(x, y, size Float) [][2]Float {
+	return [][2]Float{{x, y}, {x + size, y}, {x + size, y + size}, {x, y + size}}
+}
+
+func reverse(ring [][2]Float) [][2]Float {
+	r := make([][2]Float, len(ring))
+	for i, p := range ring {
+		r[len(ring)-1-i] = p
+	}
+	return r
+}
+
+// area returns the area of the polygon bounded by rings with the inside on
+// their left.
+func area(rings [][][2]Float) float64 {
+	a := 0.0
+	for _, ring := range rings {
+		e := predicates.PolygonArea2(ring)
+		a += float64(predicates.Estimate(len(e), &e[0])) / 2
+	}
+	return a
+}
+
+// checkSamples verifies that the points of a grid that are not on the
+// boundary of a or b are inside the result exactly when op keeps them,
+// under both fill rules for the result.
+func checkSamples(t *testing.T, a, b, result [][][2]Float, op Op, rule predicates.FillRule, lo, hi, step Float) {
+	t.Helper()
+	for x := lo; x <= hi; x += step {
+		for y := lo; y <= hi; y += step {
+			p := [2]Float{x, y}
+			la, lb := predicates.PointInPolygon(a, p, rule), predicates.PointInPolygon(b, p, rule)
+			if la != predicates.Inside && la != predicates.Outside || lb != predicates.Inside && lb != predicates.Outside {
+				continue
+			}
+			want := op.keep(la == predicates.Inside, lb == predicates.Inside)
+			for _, r := range []predicates.FillRule{predicates.NonZero, predicates.EvenOdd} {
+				l := predicates.PointInPolygon(result, p, r)
+				if l != predicates.Inside && l != predicates.Outside || (l == predicates.Inside) != want {
+					t.Fatalf("op %d: %v is %v in the result %v, want inside %v", op, p, l, result, want)
+				}
+			}
+		}
+	}
+}
+
+func TestBoolean(t *testing.T) {
+	a := [][][2]Float{square(0, 0, 2)}
+	b := [][][2]Float{square(1, 1, 2)}
+	// a square with a square hole, and a square in the hole
+	frame := [][][2]Float{square(0, 0, 6), reverse(square(1, 1, 4))}
+	tests := []struct {
+		name  string
+		a, b  [][][2]Float
+		op    Op
+		rings int
+		area  float64
+	}{
+		{name: "union", a: a, b: b, op: Union, rings: 1, area: 7},
+		{name: "intersection", a: a, b: b, op: Intersection, rings: 1, area: 1},
+		{name: "difference", a: a, b: b, op: Difference, rings: 1, area: 3},
+		{name: "xor", a: a, b: b, op: Xor, rings: 2, area: 6},
+		{name: "adjacent", a: a, b: [][][2]Float{square(2, 0, 2)}, op: Union, rings: 1, area: 8},
+		{name: "corner", a: a, b: [][][2]Float{square(2, 2, 2)}, op: Union, rings: 2, area: 8},
+		{name: "disjoint", a: a, b: [][][2]Float{square(5, 5, 1)}, op: Intersection, rings: 0, area: 0},
+		{name: "same", a: a, b: a, op: Union, rings: 1, area: 4},
+		{name: "same xor", a: a, b: a, op: Xor, rings: 0, area: 0},
+		{name: "hole", a: frame, b: [][][2]Float{square(2, 2, 2)}, op: Union, rings: 3, area: 24},
+		{name: "fill hole", a: frame, b: [][][2]Float{square(1, 1, 4)}, op: Union, rings: 1, area: 36},
+		{name: "cut hole", a: frame, b: [][][2]Float{square(-1, 2, 3)}, op: Difference, rings: 1, area: 17},
+		{name: "empty", a: nil, b: a, op: Union, rings: 1, area: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Boolean(tt.a, tt.b, tt.op, predicates.NonZero)
+			if len(got) != tt.rings {
+				t.Errorf("%d rings %v, want %d", len(got), got, tt.rings)
+			}
+			if ar := area(got); ar != tt.area {
+				t.Errorf("area %v, want %v", ar, tt.area)
+			}
+			checkSamples(t, tt.a, tt.b, got, tt.op, predicates.NonZero, -1.125, 7, 0.25)
+		})
+	}
+}
+
+func TestBooleanRectangle(t *testing.T) {
+	// the union of two adjacent squares is a rectangle, with no vertices
+	// where the squares met
+	got := Boolean([][][2]Float{square(0, 0, 1)}, [][][2]Float{square(1, 0, 1)}, Union, predicates.NonZero)
+	if len(got) != 1 || len(got[0]) != 4 {
+		t.Errorf("union = %v, want a rectangle", got)
+	}
+}
+
+func TestBooleanRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	polygon := func() [][][2]Float {
+		// self-intersecting rings on a coarse lattice have crossings,
+		// overlapping edges and shared vertices
+		rings := make([][][2]Float, 1+r.Intn(3))
+		for i := range rings {
+			rings[i] = make([][2]Float, 3+r.Intn(6))
+			for j := range rings[i] {
+				rings[i][j] = [2]Float{Float(r.Intn(9)), Float(r.Intn(9))}
+			}
+		}
+		return rings
+	}
+	for i := 0; i < 40; i++ {
+		a, b := polygon(), polygon()
+		for _, rule := range []predicates.FillRule{predicates.NonZero, predicates.EvenOdd} {
+			for op := Union; op <= Xor; op++ {
+				got := Boolean(a, b, op, rule)
+				checkSamples(t, a, b, got, op, rule, 0.0625, 8, 0.1875)
+			}
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package predicates
+
+import (
+	"math"
+	"math/big"
+)
+
+// RationalPoint2 is a constructed point in the plane, held exactly: its
+// coordinates are Num[k] / Den, where the numerators and the positive
+// denominator are nonoverlapping expansions, least significant component
+// first. Point is each coordinate rounded to the nearest Float, and Err
+// bounds the distance of each of them from the exact coordinate; it is zero
+// when Point is exact. Tests on the rounded point may disagree with the
+// exact one by as much as Err allows.
+type RationalPoint2 struct {
+	Num   [2][]Float
+	Den   []Float
+	Point [2]Float
+	Err   Float
+}
+
+// RationalPoint3 is RationalPoint2 in three dimensions.
+type RationalPoint3 struct {
+	Num   [3][]Float
+	Den   []Float
+	Point [3]Float
+	Err   Float
+}
+
+// SegmentIntersection constructs the point where the closed segments ab and
+// cd meet. It returns false if they are disjoint or overlap along a piece
+// of positive length, as SegmentsIntersect decides. The point is
+// a + t(b-a) with t = Orient2d(c, d, a) / (Orient2d(c, d, a) - Orient2d(c,
+// d, b)), that is
+//
+//	(Orient2d(c, d, a) b - Orient2d(c, d, b) a) / (Orient2d(c, d, a) - Orient2d(c, d, b))
+//
+// with the determinants as exact expansions. When the denominator vanishes,
+// because the segments are collinear and touch end to end or one of them is
+// a single point, the intersection is an endpoint and is returned as such.
+func SegmentIntersection(a, b, c, d [2]Float) (RationalPoint2, bool) {
+	var r RationalPoint2
+	switch SegmentsIntersect(a, b, c, d) {
+	case Disjoint, Overlapping:
+		return r, false
+	}
+	var w, x [12]Float
+	oa := w[:orient2dExact(c, d, a, &w)]
+	ob := x[:orient2dExact(c, d, b, &x)]
+	r.Den = sumExpansions(oa, negate(append([]Float(nil), ob...)), nil)
+	if expansionSign(r.Den) == 0 {
+		for _, p := range [4][2]Float{a, b, c, d} {
+			if onClosedSegment(a, b, p) && onClosedSegment(c, d, p) {
+				r.Num = [2][]Float{{p[0]}, {p[1]}}
+				r.Den = []Float{1}
+				r.Point = p
+				return r, true
+			}
+		}
+		panic("predicates: touching segments without a common endpoint")
+	}
+	for k := 0; k < 2; k++ {
+		r.Num[k] = sumExpansions(scale(oa, b[k]), negate(scale(ob, a[k])), nil)
+	}
+	if expansionSign(r.Den) < 0 {
+		negate(r.Den)
+		negate(r.Num[0])
+		negate(r.Num[1])
+	}
+	r.Err = round(r.Num[:], r.Den, r.Point[:])
+	return r, true
+}
+
+// LinePlaneIntersection constructs the point where the line through p and q
+// meets the plane through a, b and c. It returns false if the line is
+// parallel to the plane or lies in it, or if p and q coincide or a, b and c
+// are collinear, which all make Orient3d(a, b, c, p) and Orient3d(a, b, c,
+// q) equal. The point is
+//
+//	(Orient3d(a, b, c, p) q - Orient3d(a, b, c, q) p) / (Orient3d(a, b, c, p) - Orient3d(a, b, c, q))
+//
+// with the determinants as exact expansions.
+func LinePlaneIntersection(p, q, a, b, c [3]Float) (RationalPoint3, bool) {
+	var r RationalPoint3
+	var w, x [96]Float
+	op := w[:orient3dExact(a, b, c, p, &w)]
+	oq := x[:orient3dExact(a, b, c, q, &x)]
+	r.Den = sumExpansions(op, negate(append([]Float(nil), oq...)), nil)
+	if expansionSign(r.Den) == 0 {
+		return RationalPoint3{}, false
+	}
+	for k := 0; k < 3; k++ {
+		r.Num[k] = sumExpansions(scale(op, q[k]), negate(scale(oq, p[k])), nil)
+	}
+	if expansionSign(r.Den) < 0 {
+		for _, e := range [][]Float{r.Den, r.Num[0], r.Num[1], r.Num[2]} {
+			negate(e)
+		}
+	}
+	r.Err = round(r.Num[:], r.Den, r.Point[:])
+	return r, true
+}
+
+// onClosedSegment reports whether p lies on the closed segment ab, which may
+// be a single point.
+func onClosedSegment(a, b, p [2]Float) bool {
+	if a == b {
+		return p == a
+	}
+	return onSegment(a, b, p)
+}
+
+// round sets point to the quotients of num and den rounded to the nearest
+// Float and returns the largest of their distances to the exact quotients,
+// rounded up.
+func round(num [][]Float, den []Float, point []Float) Float {
+	d := expansionRat(den)
+	var maxErr Float
+	for k, e := range num {
+		q := new(big.Rat).Quo(expansionRat(e), d)
+		point[k] = ratFloat(q)
+		diff := new(big.Rat).Sub(q, new(big.Rat).SetFloat64(float64(point[k])))
+		diff.Abs(diff)
+		err := ratFloat(diff)
+		if new(big.Rat).SetFloat64(float64(err)).Cmp(diff) < 0 {
+			err = nextUp(err)
+		}
+		if err > maxErr {
+			maxErr = err
+		}
+	}
+	return maxErr
+}
+
+// expansionRat returns the exact value of the expansion e.
+func expansionRat(e []Float) *big.Rat {
+	s := new(big.Rat)
+	for _, x := range e {
+		s.Add(s, new(big.Rat).SetFloat64(float64(x)))
+	}
+	return s
+}
+
+// ratFloat returns x rounded to the nearest Float.
+func ratFloat(x *big.Rat) Float {
+	if floatSize == 4 {
+		f, _ := x.Float32()
+		return Float(f)
+	}
+	f, _ := x.Float64()
+	return Float(f)
+}
+
+// nextUp returns the smallest Float greater than x.
+func nextUp(x Float) Float {
+	if floatSize == 4 {
+		return Float(math.Nextafter32(float32(x), float32(math.Inf(1))))
+	}
+	return Float(math.Nextafter(float64(x), math.Inf(1)))
+}
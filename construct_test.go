@@ -0,0 +1,155 @@
+package predicates
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestSegmentIntersection(t *testing.T) {
+	tests := []struct {
+		a, b, c, d [2]Float
+		want       [2]Float
+		ok         bool
+	}{
+		{[2]Float{0, 0}, [2]Float{2, 2}, [2]Float{0, 2}, [2]Float{2, 0}, [2]Float{1, 1}, true},
+		// touching at an endpoint, including end to end on a common line
+		{[2]Float{0, 0}, [2]Float{2, 0}, [2]Float{1, 0}, [2]Float{1, 5}, [2]Float{1, 0}, true},
+		{[2]Float{0, 0}, [2]Float{1, 1}, [2]Float{1, 1}, [2]Float{3, 3}, [2]Float{1, 1}, true},
+		{[2]Float{3, 3}, [2]Float{1, 1}, [2]Float{1, 1}, [2]Float{0, 0}, [2]Float{1, 1}, true},
+		// a segment reduced to a point
+		{[2]Float{1, 1}, [2]Float{1, 1}, [2]Float{0, 0}, [2]Float{2, 2}, [2]Float{1, 1}, true},
+		{[2]Float{0, 0}, [2]Float{1, 0}, [2]Float{0, 1}, [2]Float{1, 1}, [2]Float{}, false},
+		{[2]Float{0, 0}, [2]Float{2, 0}, [2]Float{1, 0}, [2]Float{3, 0}, [2]Float{}, false},
+	}
+	for _, tt := range tests {
+		r, ok := SegmentIntersection(tt.a, tt.b, tt.c, tt.d)
+		if ok != tt.ok || ok && (r.Point != tt.want || r.Err != 0) {
+			t.Errorf("SegmentIntersection(%v, %v, %v, %v) = %v, %v, %v, want %v, %v", tt.a, tt.b, tt.c, tt.d, r.Point, r.Err, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSegmentIntersectionRand(t *testing.T) {
+	r := func(x Float) *big.Rat { return new(big.Rat).SetFloat64(float64(x)) }
+	found := 0
+	for i := 0; i < 20000; i++ {
+		var a, b, c, d [2]Float
+		for k := 0; k < 2; k++ {
+			a[k], b[k], c[k], d[k] = smallRand(), smallRand(), smallRand(), smallRand()
+		}
+		if i%2 == 0 {
+			// make c nearly on ab
+			for k := 0; k < 2; k++ {
+				c[k] = a[k] + (b[k]-a[k])*0.375
+			}
+		}
+		p, ok := SegmentIntersection(a, b, c, d)
+		if ok != (SegmentsIntersect(a, b, c, d) == Crossing || SegmentsIntersect(a, b, c, d) == Touching) {
+			t.Fatalf("SegmentIntersection(%v, %v, %v, %v) = %v, disagrees with SegmentsIntersect", a, b, c, d, ok)
+		}
+		if !ok {
+			continue
+		}
+		found++
+		// the exact point by Cramer's rule on a + s(b-a) = c + u(d-c)
+		ex, ey := new(big.Rat).Sub(r(b[0]), r(a[0])), new(big.Rat).Sub(r(b[1]), r(a[1]))
+		fx, fy := new(big.Rat).Sub(r(d[0]), r(c[0])), new(big.Rat).Sub(r(d[1]), r(c[1]))
+		gx, gy := new(big.Rat).Sub(r(c[0]), r(a[0])), new(big.Rat).Sub(r(c[1]), r(a[1]))
+		den := new(big.Rat).Sub(new(big.Rat).Mul(ex, fy), new(big.Rat).Mul(ey, fx))
+		if den.Sign() == 0 {
+			continue
+		}
+		s := new(big.Rat).Sub(new(big.Rat).Mul(gx, fy), new(big.Rat).Mul(gy, fx))
+		s.Quo(s, den)
+		want := [2]*big.Rat{
+			new(big.Rat).Add(r(a[0]), new(big.Rat).Mul(s, ex)),
+			new(big.Rat).Add(r(a[1]), new(big.Rat).Mul(s, ey)),
+		}
+		checkRational(t, p.Num[:], p.Den, p.Point[:], p.Err, want[:])
+	}
+	if found < 1000 {
+		t.Errorf("only %d intersecting pairs", found)
+	}
+}
+
+func TestLinePlaneIntersection(t *testing.T) {
+	a, b, c := [3]Float{0, 0, 1}, [3]Float{1, 0, 1}, [3]Float{0, 1, 1}
+	r, ok := LinePlaneIntersection([3]Float{0, 0, 0}, [3]Float{1, 1, 3}, a, b, c)
+	if want := [3]Float{1.0 / 3, 1.0 / 3, 1}; !ok || r.Point != want || r.Err == 0 {
+		t.Errorf("LinePlaneIntersection = %v, %v, %v, want %v with a nonzero error", r.Point, r.Err, ok, want)
+	}
+	if _, ok := LinePlaneIntersection([3]Float{0, 0, 0}, [3]Float{1, 1, 0}, a, b, c); ok {
+		t.Errorf("LinePlaneIntersection of a parallel line = true")
+	}
+	if _, ok := LinePlaneIntersection([3]Float{0, 0, 0}, [3]Float{0, 0, 5}, a, b, [3]Float{2, 0, 1}); ok {
+		t.Errorf("LinePlaneIntersection with a degenerate plane = true")
+	}
+}
+
+func TestLinePlaneIntersectionRand(t *testing.T) {
+	r := func(x Float) *big.Rat { return new(big.Rat).SetFloat64(float64(x)) }
+	for i := 0; i < 20000; i++ {
+		var p, q, a, b, c [3]Float
+		for k := 0; k < 3; k++ {
+			p[k], q[k], a[k], b[k], c[k] = smallRand(), smallRand(), smallRand(), smallRand(), smallRand()
+		}
+		if i%2 == 0 {
+			// a line nearly parallel to the plane
+			for k := 0; k < 3; k++ {
+				q[k] = p[k] + (b[k]-a[k])*0.625 + (c[k]-a[k])*0.125
+			}
+		}
+		// the exact point: p + s(q-p) with n·(p + s(q-p) - a) = 0
+		var n, u, w [3]*big.Rat
+		for k := 0; k < 3; k++ {
+			u[k] = new(big.Rat).Sub(r(b[k]), r(a[k]))
+			w[k] = new(big.Rat).Sub(r(c[k]), r(a[k]))
+		}
+		for k := 0; k < 3; k++ {
+			i, j := (k+1)%3, (k+2)%3
+			n[k] = new(big.Rat).Sub(new(big.Rat).Mul(u[i], w[j]), new(big.Rat).Mul(u[j], w[i]))
+		}
+		num, den := new(big.Rat), new(big.Rat)
+		for k := 0; k < 3; k++ {
+			num.Add(num, new(big.Rat).Mul(n[k], new(big.Rat).Sub(r(a[k]), r(p[k]))))
+			den.Add(den, new(big.Rat).Mul(n[k], new(big.Rat).Sub(r(q[k]), r(p[k]))))
+		}
+		x, ok := LinePlaneIntersection(p, q, a, b, c)
+		if ok != (den.Sign() != 0) {
+			t.Fatalf("LinePlaneIntersection(%v, %v, %v, %v, %v) = %v, want %v", p, q, a, b, c, ok, !ok)
+		}
+		if !ok {
+			continue
+		}
+		s := num.Quo(num, den)
+		var want [3]*big.Rat
+		for k := 0; k < 3; k++ {
+			want[k] = new(big.Rat).Sub(r(q[k]), r(p[k]))
+			want[k].Mul(want[k], s).Add(want[k], r(p[k]))
+		}
+		checkRational(t, x.Num[:], x.Den, x.Point[:], x.Err, want[:])
+	}
+}
+
+// checkRational checks a constructed point against the exact coordinates
+// want: the expansions must have the exact values, the rounded point must
+// be correctly rounded and err must bound its error.
+func checkRational(t *testing.T, num [][]Float, den []Float, point []Float, err Float, want []*big.Rat) {
+	t.Helper()
+	if expansionRat(den).Sign() <= 0 {
+		t.Fatalf("denominator %v not positive", den)
+	}
+	d := expansionRat(den)
+	for k, w := range want {
+		if got := new(big.Rat).Quo(expansionRat(num[k]), d); got.Cmp(w) != 0 {
+			t.Fatalf("coordinate %d = %v, want %v", k, got.FloatString(20), w.FloatString(20))
+		}
+		if point[k] != ratFloat(w) {
+			t.Fatalf("rounded coordinate %d = %v, want %v", k, point[k], ratFloat(w))
+		}
+		diff := new(big.Rat).Sub(w, new(big.Rat).SetFloat64(float64(point[k])))
+		if diff.Abs(diff).Cmp(new(big.Rat).SetFloat64(float64(err))) > 0 {
+			t.Fatalf("error of coordinate %d is %v, more than the bound %v", k, diff.FloatString(20), err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package predicates
+
+// CoplanarOrient3d returns the orientation of the points a, b and c seen
+// from the side of their plane that normalHint points to: positive if they
+// are counterclockwise, negative if clockwise and zero if they are
+// collinear. It is the sign of the triple product normalHint·((b-a)×(c-a)),
+// evaluated in floating point and then exactly if the error bound does not
+// settle it, like Orient3d.
+//
+// This is Orient2d on the projection of the points onto the coordinate
+// plane of the dominant axis of the normal of their plane, negated if that
+// component of the normal is negative, but the sign is the same for any
+// hint less than 90 degrees from the normal, so no axis has to be chosen
+// and a projection that degenerates to a segment cannot occur. The normal
+// of any non-degenerate triangle in the plane, even one rounded to Float,
+// is a good hint. A hint that is zero or perpendicular to the normal gives
+// zero.
+func CoplanarOrient3d(a, b, c, normalHint [3]Float) Float {
+	var det, permanent Float
+	for k := 0; k < 3; k++ {
+		i, j := (k+1)%3, (k+2)%3
+		// the component k of (b-a)×(c-a), as Orient2d computes it
+		left := (a[i] - c[i]) * (b[j] - c[j])
+		right := (a[j] - c[j]) * (b[i] - c[i])
+		det += normalHint[k] * (left - right)
+		permanent += abs(normalHint[k]) * (abs(left) + abs(right))
+	}
+	errbound := o3derrboundA * permanent
+	if det > errbound || -det > errbound {
+		return det
+	}
+	return CoplanarOrient3dExact(a, b, c, normalHint)
+}
+
+// CoplanarOrient3dExact returns the most significant component of the
+// exact triple product of CoplanarOrient3d, which has its sign.
+func CoplanarOrient3dExact(a, b, c, normalHint [3]Float) Float {
+	var sum []Float
+	for k := 0; k < 3; k++ {
+		if normalHint[k] == 0 {
+			continue
+		}
+		var w [12]Float
+		n := orient2dExact(project2(a, k), project2(b, k), project2(c, k), &w)
+		t := make([]Float, 2*n)
+		t = t[:ScaleExpansionZeroElim(n, &w[0], normalHint[k], &t[0])]
+		sum = sumExpansions(sum, t, nil)
+	}
+	if len(sum) == 0 {
+		return 0
+	}
+	return sum[len(sum)-1]
+}
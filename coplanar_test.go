@@ -0,0 +1,89 @@
+package predicates
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestCoplanarOrient3d(t *testing.T) {
+	tests := []struct {
+		a, b, c, n [3]Float
+		want       Float
+	}{
+		{[3]Float{0, 0, 0}, [3]Float{1, 0, 0}, [3]Float{0, 1, 0}, [3]Float{0, 0, 1}, 1},
+		{[3]Float{0, 0, 0}, [3]Float{1, 0, 0}, [3]Float{0, 1, 0}, [3]Float{0, 0, -1}, -1},
+		// rough hints, 30 and almost 90 degrees off the normal
+		{[3]Float{0, 0, 0}, [3]Float{1, 0, 0}, [3]Float{0, 1, 0}, [3]Float{0.5, -0.25, 0.866}, 1},
+		{[3]Float{0, 0, 0}, [3]Float{1, 0, 0}, [3]Float{0, 1, 0}, [3]Float{100, -70, 0.01}, 1},
+		// a hint in the plane, or zero, gives zero
+		{[3]Float{0, 0, 0}, [3]Float{1, 0, 0}, [3]Float{0, 1, 0}, [3]Float{1, 1, 0}, 0},
+		{[3]Float{0, 0, 0}, [3]Float{1, 0, 0}, [3]Float{0, 1, 0}, [3]Float{0, 0, 0}, 0},
+		{[3]Float{0, 0, 0}, [3]Float{1, 1, 1}, [3]Float{2, 2, 2}, [3]Float{1, -1, 0}, 0},
+		// collinear points need no hint
+		{[3]Float{0, 0, 0}, [3]Float{1, 1, 1}, [3]Float{2, 2, 2}, [3]Float{0, 0, 0}, 0},
+		// the plane x + y + z = 3, seen from both sides
+		{[3]Float{3, 0, 0}, [3]Float{0, 3, 0}, [3]Float{0, 0, 3}, [3]Float{1, 1, 1}, 1},
+		{[3]Float{3, 0, 0}, [3]Float{0, 0, 3}, [3]Float{0, 3, 0}, [3]Float{1, 1, 1}, -1},
+		{[3]Float{3, 0, 0}, [3]Float{0, 0, 3}, [3]Float{0, 3, 0}, [3]Float{-1, -1, -1}, 1},
+		// the projection on the z = 0 plane is degenerate, the hint picks x
+		{[3]Float{0, 0, 0}, [3]Float{1, 1, 0}, [3]Float{0, 0, 1}, [3]Float{1, -1, 0}, 1},
+	}
+	for _, tt := range tests {
+		if got := CoplanarOrient3d(tt.a, tt.b, tt.c, tt.n); !isSamePred(got, tt.want) {
+			t.Errorf("CoplanarOrient3d(%v, %v, %v, %v) = %v, want sign of %v", tt.a, tt.b, tt.c, tt.n, got, tt.want)
+		}
+		if got := CoplanarOrient3dExact(tt.a, tt.b, tt.c, tt.n); !isSamePred(got, tt.want) {
+			t.Errorf("CoplanarOrient3dExact(%v, %v, %v, %v) = %v, want sign of %v", tt.a, tt.b, tt.c, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestCoplanarOrient3dRand(t *testing.T) {
+	r := func(x Float) *big.Rat { return new(big.Rat).SetFloat64(float64(x)) }
+	for i := 0; i < 20000; i++ {
+		// points on a common plane through a, spanned by lattice vectors
+		var a, b, c, n, u, v [3]Float
+		for k := 0; k < 3; k++ {
+			a[k] = Float(random()%2001 - 1000)
+			u[k], v[k] = Float(random()%21-10), Float(random()%21-10)
+		}
+		s, t1 := Float(random()%41-20), Float(random()%41-20)
+		s2, t2 := Float(random()%41-20), Float(random()%41-20)
+		if i%2 == 1 {
+			// nearly collinear points
+			s2, t2 = 3*s+Float(random()%3-1), 3*t1+Float(random()%3-1)
+		}
+		for k := 0; k < 3; k++ {
+			b[k] = a[k] + s*u[k] + t1*v[k]
+			c[k] = a[k] + s2*u[k] + t2*v[k]
+		}
+		// the normal of the plane, and a hint anywhere on its side
+		var normal [3]Float
+		for k := 0; k < 3; k++ {
+			i, j := (k+1)%3, (k+2)%3
+			normal[k] = u[i]*v[j] - u[j]*v[i]
+		}
+		var m Float
+		for k := 0; k < 3; k++ {
+			n[k] = Float(random()%2001 - 1000)
+			m += n[k] * normal[k]
+		}
+		if m <= 0 {
+			continue
+		}
+		// the exact triple product normal·((b-a)×(c-a))
+		want := new(big.Rat)
+		for k := 0; k < 3; k++ {
+			i, j := (k+1)%3, (k+2)%3
+			x := new(big.Rat).Mul(new(big.Rat).Sub(r(b[i]), r(a[i])), new(big.Rat).Sub(r(c[j]), r(a[j])))
+			y := new(big.Rat).Mul(new(big.Rat).Sub(r(b[j]), r(a[j])), new(big.Rat).Sub(r(c[i]), r(a[i])))
+			want.Add(want, x.Sub(x, y).Mul(x, r(normal[k])))
+		}
+		if got := CoplanarOrient3d(a, b, c, n); sign(got) != want.Sign() {
+			t.Errorf("CoplanarOrient3d(%v, %v, %v, %v) = %v, want sign %d", a, b, c, n, got, want.Sign())
+		}
+		if got := CoplanarOrient3dExact(a, b, c, n); sign(got) != want.Sign() {
+			t.Errorf("CoplanarOrient3dExact(%v, %v, %v, %v) = %v, want sign %d", a, b, c, n, got, want.Sign())
+		}
+	}
+}
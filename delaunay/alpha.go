@@ -0,0 +1,348 @@
+package delaunay
+
+import (
+	"math"
+	"sort"
+
+	"github.com/toy80/predicates"
+)
+
+// AlphaShape is the alpha complex of a set of points in the plane: the
+// simplices of the Delaunay triangulation whose smallest circumcircle has a
+// radius of at most alpha and holds no other point, together with all their
+// faces. A triangle is always its own smallest circle; an edge whose
+// diametral circle holds the opposite vertex of a neighbouring triangle is
+// attached to it and only belongs to the complex as one of its edges.
+type AlphaShape struct {
+	Points [][2]Float
+	// Triangles are the counterclockwise triangles of the complex.
+	Triangles [][3]int
+	// Boundary are the edges with a triangle of the complex on exactly one
+	// side, oriented so that it is on their left.
+	Boundary [][2]int
+	// Singular are the edges of the complex without any of its triangles,
+	// smaller index first.
+	Singular [][2]int
+	// Isolated are the vertices of the complex without any of its edges.
+	Isolated []int
+}
+
+// AlphaShape returns the alpha complex of the points of the triangulation
+// for the radius alpha. Circumradii are compared with alpha exactly, and an
+// edge is attached when Incircle2p finds a vertex inside its diametral
+// circle. Constrained segments are ignored: the shape is defined by the
+// Delaunay triangulation, which tr must be.
+func (tr *Triangulation) AlphaShape(alpha Float) *AlphaShape {
+	s := &AlphaShape{Points: tr.points}
+	r := newRadius(alpha)
+	m := tr.Mesh()
+	used := make([]bool, len(tr.points))
+	if len(m.Triangles) == 0 {
+		// collinear points: the edges join consecutive points, and no
+		// point can be inside their diametral circles
+		sites := append([]int(nil), tr.pending...)
+		sort.Slice(sites, func(i, j int) bool {
+			return lessXY(tr.points[sites[i]], tr.points[sites[j]])
+		})
+		for i := 1; i < len(sites); i++ {
+			a, b := sites[i-1], sites[i]
+			if r.edge(point3(tr.points[a]), point3(tr.points[b])) {
+				s.Singular = append(s.Singular, sortedEdge(a, b))
+				used[a], used[b] = true, true
+			}
+		}
+		sort.Ints(sites)
+		for _, v := range sites {
+			if !used[v] {
+				s.Isolated = append(s.Isolated, v)
+			}
+		}
+		sortEdges(s.Singular)
+		return s
+	}
+
+	in := make([]bool, len(m.Triangles))
+	present := make([]bool, len(tr.points))
+	for t, v := range m.Triangles {
+		a, b, c := point3(tr.points[v[0]]), point3(tr.points[v[1]]), point3(tr.points[v[2]])
+		in[t] = r.triangle(a, b, c)
+		if in[t] {
+			s.Triangles = append(s.Triangles, v)
+		}
+		for _, u := range v {
+			present[u] = true
+		}
+	}
+	for t, v := range m.Triangles {
+		for i := 0; i < 3; i++ {
+			u := m.Neighbors[t][i]
+			a, b := v[(i+1)%3], v[(i+2)%3]
+			switch {
+			case in[t]:
+				if u < 0 || !in[u] {
+					s.Boundary = append(s.Boundary, [2]int{a, b})
+				}
+				used[a], used[b] = true, true
+			case u >= 0 && in[u]:
+				// a boundary edge of u
+			case u < 0 || t < u:
+				pa, pb := tr.points[a], tr.points[b]
+				if predicates.Incircle2p(pa, pb, tr.points[v[i]]) > 0 {
+					continue
+				}
+				if u >= 0 {
+					w := m.Triangles[u][m.edgeTo(u, t)]
+					if predicates.Incircle2p(pa, pb, tr.points[w]) > 0 {
+						continue
+					}
+				}
+				if r.edge(point3(pa), point3(pb)) {
+					s.Singular = append(s.Singular, sortedEdge(a, b))
+					used[a], used[b] = true, true
+				}
+			}
+		}
+	}
+	for v := range tr.points {
+		if present[v] && !used[v] {
+			s.Isolated = append(s.Isolated, v)
+		}
+	}
+	sortEdges(s.Boundary)
+	sortEdges(s.Singular)
+	return s
+}
+
+// edgeTo returns the index of the edge of the mesh triangle t shared with
+// triangle u.
+func (m *Mesh) edgeTo(t, u int) int {
+	for i, n := range m.Neighbors[t] {
+		if n == u {
+			return i
+		}
+	}
+	return -1
+}
+
+// AlphaShape3 is the alpha complex of a set of points in space, defined as
+// for AlphaShape with circumspheres: a triangle or an edge whose smallest
+// circumsphere holds a vertex of a tetrahedron around it is attached.
+type AlphaShape3 struct {
+	Points [][3]Float
+	// Tetrahedra are the positively oriented tetrahedra of the complex.
+	Tetrahedra [][4]int
+	// Boundary are the faces with a tetrahedron of the complex on exactly
+	// one side, counterclockwise seen from the other side.
+	Boundary [][3]int
+	// SingularFaces are the triangles of the complex without any of its
+	// tetrahedra, as vertex indices in increasing order.
+	SingularFaces [][3]int
+	// SingularEdges are the edges of the complex without any of its
+	// triangles, smaller index first.
+	SingularEdges [][2]int
+	// Isolated are the vertices of the complex without any of its edges.
+	Isolated []int
+}
+
+// faceVertices lists the vertices of the face opposite each vertex of a
+// positively oriented tetrahedron, counterclockwise seen from outside.
+var faceVertices = [4][3]int{{1, 3, 2}, {0, 2, 3}, {0, 3, 1}, {0, 1, 2}}
+
+// AlphaShape returns the alpha complex of the points of the
+// tetrahedralization for the radius alpha, with every circumradius and
+// every attachment decided exactly. While the points are all coplanar there
+// are no tetrahedra to take the complex from, and only the vertices are
+// returned, as Isolated.
+func (tr *Tetrahedralization) AlphaShape(alpha Float) *AlphaShape3 {
+	s := &AlphaShape3{Points: tr.points}
+	r := newRadius(alpha)
+	m := tr.Mesh()
+	if len(m.Tetrahedra) == 0 {
+		s.Isolated = append(s.Isolated, tr.pending...)
+		sort.Ints(s.Isolated)
+		return s
+	}
+
+	in := make([]bool, len(m.Tetrahedra))
+	present := make([]bool, len(tr.points))
+	// link holds the vertices of the tetrahedra around each edge
+	link := make(map[[2]int][]int)
+	for t, v := range m.Tetrahedra {
+		in[t] = r.tetrahedron(tr.points[v[0]], tr.points[v[1]], tr.points[v[2]], tr.points[v[3]])
+		if in[t] {
+			s.Tetrahedra = append(s.Tetrahedra, v)
+		}
+		for i, a := range v {
+			present[a] = true
+			for j := i + 1; j < 4; j++ {
+				e := sortedEdge(a, v[j])
+				for _, w := range v {
+					if w != a && w != v[j] {
+						link[e] = append(link[e], w)
+					}
+				}
+			}
+		}
+	}
+
+	inFace := make(map[[2]int]bool)
+	addFace := func(f [3]int) {
+		for i := 0; i < 3; i++ {
+			inFace[sortedEdge(f[i], f[(i+1)%3])] = true
+		}
+	}
+	for t, v := range m.Tetrahedra {
+		for i := 0; i < 4; i++ {
+			u := m.Neighbors[t][i]
+			var f [3]int
+			for k, j := range faceVertices[i] {
+				f[k] = v[j]
+			}
+			switch {
+			case in[t]:
+				if u < 0 || !in[u] {
+					s.Boundary = append(s.Boundary, f)
+				}
+				addFace(f)
+			case u >= 0 && in[u]:
+				// a boundary face of u
+			case u < 0 || t < u:
+				a, b, c := tr.points[f[0]], tr.points[f[1]], tr.points[f[2]]
+				if predicates.Insphere3p(a, b, c, tr.points[v[i]]) > 0 {
+					continue
+				}
+				if u >= 0 {
+					w := m.Tetrahedra[u][m.faceTo(u, t)]
+					if predicates.Insphere3p(a, b, c, tr.points[w]) > 0 {
+						continue
+					}
+				}
+				if r.triangle(a, b, c) {
+					sort.Ints(f[:])
+					s.SingularFaces = append(s.SingularFaces, f)
+					addFace(f)
+				}
+			}
+		}
+	}
+
+	used := make([]bool, len(tr.points))
+	for e, ws := range link {
+		if !inFace[e] {
+			a, b := tr.points[e[0]], tr.points[e[1]]
+			attached := false
+			for _, w := range ws {
+				if predicates.DotSign3d(a, b, tr.points[w]) < 0 {
+					attached = true
+					break
+				}
+			}
+			if attached || !r.edge(a, b) {
+				continue
+			}
+			s.SingularEdges = append(s.SingularEdges, e)
+		}
+		used[e[0]], used[e[1]] = true, true
+	}
+	for v := range tr.points {
+		if present[v] && !used[v] {
+			s.Isolated = append(s.Isolated, v)
+		}
+	}
+	sortFaces(s.Boundary)
+	sortFaces(s.SingularFaces)
+	sortEdges(s.SingularEdges)
+	return s
+}
+
+// faceTo returns the index of the face of the mesh tetrahedron t shared
+// with tetrahedron u.
+func (m *Mesh3) faceTo(t, u int) int {
+	for i, n := range m.Neighbors[t] {
+		if n == u {
+			return i
+		}
+	}
+	return -1
+}
+
+// radius compares circumradii with alpha. Each comparison first checks the
+// edges in float64, as no circumsphere is narrower than the longest, which
+// rejects most large simplices in float64; the rest is exact.
+type radius struct {
+	alpha Float
+	max64 float64 // a float64 above 4 alpha^2 by more than the rounding of tooLong
+}
+
+func newRadius(alpha Float) *radius {
+	max64 := 4 * float64(alpha) * float64(alpha)
+	if max64 < 1e-290 {
+		// underflow would make the filter wrong
+		max64 = math.Inf(1)
+	}
+	return &radius{alpha, max64 * (1 + 1e-9)}
+}
+
+// tooLong reports whether a squared edge length, computed in float64,
+// certainly exceeds the squared diameter.
+func (r *radius) tooLong(a, b [3]Float) bool {
+	var l float64
+	for k := 0; k < 3; k++ {
+		d := float64(a[k]) - float64(b[k])
+		l += d * d
+	}
+	return l > r.max64
+}
+
+// edge reports whether the diametral sphere of ab has a radius of at most
+// alpha, that is whether ab is at most as long as a diameter.
+func (r *radius) edge(a, b [3]Float) bool {
+	return !r.tooLong(a, b) && predicates.CompareDistance3d(a, b, [3]Float{}, [3]Float{2 * r.alpha}) <= 0
+}
+
+// triangle reports whether the circumcircle of the triangle abc has a
+// radius of at most alpha.
+func (r *radius) triangle(a, b, c [3]Float) bool {
+	if r.tooLong(a, b) || r.tooLong(b, c) || r.tooLong(c, a) {
+		return false
+	}
+	return predicates.CompareTriangleCircumradius3d(a, b, c, r.alpha) <= 0
+}
+
+// tetrahedron reports whether the circumsphere of the tetrahedron abcd has
+// a radius of at most alpha.
+func (r *radius) tetrahedron(a, b, c, d [3]Float) bool {
+	if r.tooLong(a, b) || r.tooLong(a, c) || r.tooLong(a, d) ||
+		r.tooLong(b, c) || r.tooLong(b, d) || r.tooLong(c, d) {
+		return false
+	}
+	return predicates.CompareCircumradius3d(a, b, c, d, r.alpha) <= 0
+}
+
+func point3(p [2]Float) [3]Float {
+	return [3]Float{p[0], p[1], 0}
+}
+
+func sortedEdge(a, b int) [2]int {
+	if b < a {
+		a, b = b, a
+	}
+	return [2]int{a, b}
+}
+
+func sortEdges(es [][2]int) {
+	sort.Slice(es, func(i, j int) bool {
+		return es[i][0] < es[j][0] || es[i][0] == es[j][0] && es[i][1] < es[j][1]
+	})
+}
+
+func sortFaces(fs [][3]int) {
+	sort.Slice(fs, func(i, j int) bool {
+		for k := range fs[i] {
+			if fs[i][k] != fs[j][k] {
+				return fs[i][k] < fs[j][k]
+			}
+		}
+		return false
+	})
+}
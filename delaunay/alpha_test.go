@@ -0,0 +1,370 @@
+package delaunay
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/toy80/predicates"
+)
+
+type vec [3]float64
+
+func vec3(p [3]Float) vec {
+	return vec{float64(p[0]), float64(p[1]), float64(p[2])}
+}
+
+func (a vec) sub(b vec) vec     { return vec{a[0] - b[0], a[1] - b[1], a[2] - b[2]} }
+func (a vec) dot(b vec) float64 { return a[0]*b[0] + a[1]*b[1] + a[2]*b[2] }
+func (a vec) cross(b vec) vec {
+	return vec{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+
+// center3 returns the center of the smallest sphere through a, b and c.
+func center3(a, b, c vec) vec {
+	u, v := b.sub(a), c.sub(a)
+	n := u.cross(v)
+	k1, k2, d := u.dot(u), v.dot(v), 2*n.dot(n)
+	p, q := v.cross(n), n.cross(u)
+	return vec{a[0] + (k1*p[0]+k2*q[0])/d, a[1] + (k1*p[1]+k2*q[1])/d, a[2] + (k1*p[2]+k2*q[2])/d}
+}
+
+// center4 returns the center of the sphere through a, b, c and d.
+func center4(a, b, c, d vec) vec {
+	u, v, w := b.sub(a), c.sub(a), d.sub(a)
+	vw, wu, uv := v.cross(w), w.cross(u), u.cross(v)
+	det := 2 * u.dot(vw)
+	var x vec
+	for k := range x {
+		x[k] = a[k] + (u.dot(u)*vw[k]+v.dot(v)*wu[k]+w.dot(w)*uv[k])/det
+	}
+	return x
+}
+
+// empty reports whether no point other than the vertices is strictly
+// inside the sphere of center c through the point on.
+func empty(points [][3]Float, c, on vec, vertices ...int) bool {
+	r := c.sub(on).dot(c.sub(on))
+next:
+	for i, p := range points {
+		for _, v := range vertices {
+			if i == v {
+				continue next
+			}
+		}
+		if d := c.sub(vec3(p)); d.dot(d) < r*(1-1e-9) {
+			return false
+		}
+	}
+	return true
+}
+
+func edgeSet(es [][2]int) map[[2]int]bool {
+	m := make(map[[2]int]bool)
+	for _, e := range es {
+		m[e] = true
+	}
+	return m
+}
+
+func sameInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// checkAlpha compares s with the alpha complex of the Delaunay
+// triangulation m computed from its definition, testing every point for
+// the emptiness of the smallest circles.
+func checkAlpha(t *testing.T, m *Mesh, s *AlphaShape, alpha float64) {
+	t.Helper()
+	points := make([][3]Float, len(m.Points))
+	for i, p := range m.Points {
+		points[i] = point3(p)
+	}
+	var tris [][3]int
+	count := make(map[[2]int]int)
+	for _, v := range m.Triangles {
+		a, b, c := vec3(points[v[0]]), vec3(points[v[1]]), vec3(points[v[2]])
+		o := center3(a, b, c)
+		if d := o.sub(a); math.Sqrt(d.dot(d)) <= alpha {
+			tris = append(tris, v)
+			for i := 0; i < 3; i++ {
+				count[sortedEdge(v[i], v[(i+1)%3])]++
+			}
+		}
+	}
+	var boundary, singular [][2]int
+	used := make(map[int]bool)
+	seen := make(map[[2]int]bool)
+	for _, v := range m.Triangles {
+		for i := 0; i < 3; i++ {
+			a, b := v[i], v[(i+1)%3]
+			e := sortedEdge(a, b)
+			switch n := count[e]; {
+			case n == 1:
+				for _, w := range tris {
+					if w == v {
+						boundary = append(boundary, [2]int{a, b})
+					}
+				}
+			case n == 0 && !seen[e]:
+				seen[e] = true
+				pa, pb := vec3(points[a]), vec3(points[b])
+				c := vec{(pa[0] + pb[0]) / 2, (pa[1] + pb[1]) / 2, 0}
+				if d := c.sub(pa); math.Sqrt(d.dot(d)) <= alpha && empty(points, c, pa, a, b) {
+					singular = append(singular, e)
+					count[e] = -1
+				}
+			}
+			if count[e] != 0 {
+				used[a], used[b] = true, true
+			}
+		}
+	}
+	var isolated []int
+	for v := range m.Points {
+		if !used[v] {
+			isolated = append(isolated, v)
+		}
+	}
+	sortEdges(boundary)
+	sortEdges(singular)
+	if len(s.Triangles) != len(tris) {
+		t.Errorf("alpha %v: %d triangles, want %d", alpha, len(s.Triangles), len(tris))
+	}
+	if got, want := edgeSet(s.Boundary), edgeSet(boundary); len(got) != len(want) || len(s.Boundary) != len(boundary) {
+		t.Errorf("alpha %v: %d boundary edges, want %d", alpha, len(s.Boundary), len(boundary))
+	} else {
+		for e := range want {
+			if !got[e] {
+				t.Errorf("alpha %v: boundary edge %v missing", alpha, e)
+			}
+		}
+	}
+	if len(s.Singular) != len(singular) {
+		t.Errorf("alpha %v: singular edges %v, want %v", alpha, s.Singular, singular)
+	} else {
+		for i := range singular {
+			if s.Singular[i] != singular[i] {
+				t.Errorf("alpha %v: singular edges %v, want %v", alpha, s.Singular, singular)
+				break
+			}
+		}
+	}
+	if !sameInts(s.Isolated, isolated) {
+		t.Errorf("alpha %v: isolated %v, want %v", alpha, s.Isolated, isolated)
+	}
+}
+
+func TestAlphaShapeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	points := make([][2]Float, 300)
+	for i := range points {
+		points[i] = [2]Float{Float(r.Float64()), Float(r.Float64())}
+	}
+	tr := New(points)
+	m := tr.Mesh()
+	for _, alpha := range []float64{0, 0.01, 0.03, 0.05, 0.1, 1, 10} {
+		checkAlpha(t, m, tr.AlphaShape(Float(alpha)), alpha)
+	}
+}
+
+func TestAlphaShapeLattice(t *testing.T) {
+	// on a lattice of spacing 2 the edges have diametral circles of radius
+	// 1 with the opposite vertices on them, and the triangles radius √2
+	const n = 5
+	var points [][2]Float
+	for j := 0; j <= n; j++ {
+		for i := 0; i <= n; i++ {
+			points = append(points, [2]Float{Float(2 * i), Float(2 * j)})
+		}
+	}
+	tr := New(points)
+	tests := []struct {
+		alpha                                 Float
+		triangles, boundary, singular, single int
+	}{
+		{alpha: 0.5, single: (n + 1) * (n + 1)},
+		{alpha: 1, singular: 2 * n * (n + 1)},
+		{alpha: 1.5, triangles: 2 * n * n, boundary: 4 * n},
+	}
+	for _, tt := range tests {
+		s := tr.AlphaShape(tt.alpha)
+		if len(s.Triangles) != tt.triangles || len(s.Boundary) != tt.boundary || len(s.Singular) != tt.singular || len(s.Isolated) != tt.single {
+			t.Errorf("alpha %v: %d triangles, %d boundary, %d singular, %d isolated, want %d, %d, %d, %d", tt.alpha,
+				len(s.Triangles), len(s.Boundary), len(s.Singular), len(s.Isolated),
+				tt.triangles, tt.boundary, tt.singular, tt.single)
+		}
+	}
+}
+
+func TestAlphaShapeDegenerate(t *testing.T) {
+	s := New(nil).AlphaShape(1)
+	if len(s.Triangles)+len(s.Boundary)+len(s.Singular)+len(s.Isolated) != 0 {
+		t.Errorf("empty: %+v", s)
+	}
+	// collinear points, with a duplicate
+	points := [][2]Float{{0, 0}, {3, 3}, {1, 1}, {1, 1}, {5, 5}}
+	s = New(points).AlphaShape(1)
+	if want := [][2]int{{0, 2}}; len(s.Singular) != 1 || s.Singular[0] != want[0] {
+		t.Errorf("collinear singular = %v, want %v", s.Singular, want)
+	}
+	if want := []int{1, 4}; !sameInts(s.Isolated, want) {
+		t.Errorf("collinear isolated = %v, want %v", s.Isolated, want)
+	}
+}
+
+func TestAlphaShape3Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	points := make([][3]Float, 150)
+	for i := range points {
+		points[i] = [3]Float{Float(r.Float64()), Float(r.Float64()), Float(r.Float64())}
+	}
+	tr := NewTetrahedralization(points)
+	m := tr.Mesh()
+	for _, alpha := range []float64{0, 0.05, 0.1, 0.15, 0.2, 0.3, 10} {
+		s := tr.AlphaShape(Float(alpha))
+
+		// the complex from its definition
+		faces := make(map[[3]int]int)
+		link := make(map[[2]int]bool)
+		sorted := func(f [3]int) [3]int {
+			for i := 0; i < 3; i++ {
+				for j := i + 1; j < 3; j++ {
+					if f[j] < f[i] {
+						f[i], f[j] = f[j], f[i]
+					}
+				}
+			}
+			return f
+		}
+		tets := 0
+		for _, v := range m.Tetrahedra {
+			a, b, c, d := vec3(points[v[0]]), vec3(points[v[1]]), vec3(points[v[2]]), vec3(points[v[3]])
+			o := center4(a, b, c, d)
+			in := math.Sqrt(o.sub(a).dot(o.sub(a))) <= alpha
+			if in {
+				tets++
+			}
+			for i := 0; i < 4; i++ {
+				f := sorted([3]int{v[(i+1)%4], v[(i+2)%4], v[(i+3)%4]})
+				if in {
+					faces[f]++
+				} else if _, ok := faces[f]; !ok {
+					faces[f] = 0
+				}
+				for j := i + 1; j < 4; j++ {
+					link[sortedEdge(v[i], v[j])] = true
+				}
+			}
+		}
+		boundary := 0
+		var singularFaces [][3]int
+		inFace := make(map[[2]int]bool)
+		for f, n := range faces {
+			if n == 0 {
+				a, b, c := vec3(points[f[0]]), vec3(points[f[1]]), vec3(points[f[2]])
+				o := center3(a, b, c)
+				if math.Sqrt(o.sub(a).dot(o.sub(a))) > alpha || !empty(points, o, a, f[0], f[1], f[2]) {
+					continue
+				}
+				singularFaces = append(singularFaces, f)
+			}
+			if n == 1 {
+				boundary++
+			}
+			for i := 0; i < 3; i++ {
+				inFace[sortedEdge(f[i], f[(i+1)%3])] = true
+			}
+		}
+		var singularEdges [][2]int
+		used := make(map[int]bool)
+		for e := range link {
+			if !inFace[e] {
+				a, b := vec3(points[e[0]]), vec3(points[e[1]])
+				o := vec{(a[0] + b[0]) / 2, (a[1] + b[1]) / 2, (a[2] + b[2]) / 2}
+				if math.Sqrt(o.sub(a).dot(o.sub(a))) > alpha || !empty(points, o, a, e[0], e[1]) {
+					continue
+				}
+				singularEdges = append(singularEdges, e)
+			}
+			used[e[0]], used[e[1]] = true, true
+		}
+		sortFaces(singularFaces)
+		sortEdges(singularEdges)
+
+		if len(s.Tetrahedra) != tets || len(s.Boundary) != boundary {
+			t.Errorf("alpha %v: %d tetrahedra, %d boundary faces, want %d, %d", alpha, len(s.Tetrahedra), len(s.Boundary), tets, boundary)
+		}
+		if len(s.SingularFaces) != len(singularFaces) {
+			t.Errorf("alpha %v: %d singular faces, want %d", alpha, len(s.SingularFaces), len(singularFaces))
+		} else {
+			for i := range singularFaces {
+				if s.SingularFaces[i] != singularFaces[i] {
+					t.Errorf("alpha %v: singular face %v, want %v", alpha, s.SingularFaces[i], singularFaces[i])
+				}
+			}
+		}
+		if len(s.SingularEdges) != len(singularEdges) {
+			t.Errorf("alpha %v: %d singular edges, want %d", alpha, len(s.SingularEdges), len(singularEdges))
+		} else {
+			for i := range singularEdges {
+				if s.SingularEdges[i] != singularEdges[i] {
+					t.Errorf("alpha %v: singular edge %v, want %v", alpha, s.SingularEdges[i], singularEdges[i])
+				}
+			}
+		}
+		if len(s.Isolated) != len(points)-len(used) {
+			t.Errorf("alpha %v: %d isolated, want %d", alpha, len(s.Isolated), len(points)-len(used))
+		}
+	}
+}
+
+func TestAlphaShape3Lattice(t *testing.T) {
+	// a lattice of spacing 2: edges have radius 1, the triangles in the
+	// lattice squares √2 and the tetrahedra √3; the other triangles are
+	// attached or larger
+	const n = 2
+	var points [][3]Float
+	for i := 0; i <= n; i++ {
+		for j := 0; j <= n; j++ {
+			for k := 0; k <= n; k++ {
+				points = append(points, [3]Float{Float(2 * i), Float(2 * j), Float(2 * k)})
+			}
+		}
+	}
+	tr := NewTetrahedralization(points)
+	squares := 3 * n * n * (n + 1)
+	tests := []struct {
+		alpha                                      Float
+		tetrahedra, boundary, faces, edges, single int
+	}{
+		{alpha: 0.5, single: len(points)},
+		{alpha: 1, edges: 3 * n * (n + 1) * (n + 1)},
+		{alpha: 1.5, faces: 2 * squares},
+		{alpha: 1.8, tetrahedra: len(tr.Mesh().Tetrahedra), boundary: 12 * n * n},
+	}
+	for _, tt := range tests {
+		s := tr.AlphaShape(tt.alpha)
+		if len(s.Tetrahedra) != tt.tetrahedra || len(s.Boundary) != tt.boundary || len(s.SingularFaces) != tt.faces ||
+			len(s.SingularEdges) != tt.edges || len(s.Isolated) != tt.single {
+			t.Errorf("alpha %v: %d tetrahedra, %d boundary, %d faces, %d edges, %d isolated, want %d, %d, %d, %d, %d", tt.alpha,
+				len(s.Tetrahedra), len(s.Boundary), len(s.SingularFaces), len(s.SingularEdges), len(s.Isolated),
+				tt.tetrahedra, tt.boundary, tt.faces, tt.edges, tt.single)
+		}
+	}
+	// the boundary is oriented outwards
+	for _, f := range tr.AlphaShape(1.8).Boundary {
+		c := [3]Float{2, 2, 2}
+		if o := predicates.Orient3d(points[f[0]], points[f[1]], points[f[2]], c); o <= 0 {
+			t.Errorf("boundary face %v is oriented inwards", f)
+		}
+	}
+}
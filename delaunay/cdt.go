@@ -0,0 +1,296 @@
+package delaunay
+
+import (
+	"errors"
+
+	"github.com/toy80/predicates"
+)
+
+var (
+	// ErrCrossing is returned when a segment crosses a constrained segment
+	// already in the triangulation, their intersection is not a vertex.
+	ErrCrossing = errors.New("delaunay: segment crosses a constrained segment")
+	// ErrFlat is returned when segments are inserted while the points are
+	// all collinear and there are no triangles to hold them.
+	ErrFlat = errors.New("delaunay: triangulation has no triangles")
+	// ErrVertex is returned for segments with a vertex index that is out of
+	// range or was left out as a duplicate.
+	ErrVertex = errors.New("delaunay: invalid segment vertex")
+)
+
+// NewConstrained returns the constrained Delaunay triangulation of points
+// that has the given segments, pairs of indices into points, as edges.
+func NewConstrained(points [][2]Float, segments [][2]int) (*Triangulation, error) {
+	tr := New(points)
+	for _, s := range segments {
+		if err := tr.InsertSegment(s[0], s[1]); err != nil {
+			return tr, err
+		}
+	}
+	return tr, nil
+}
+
+// around calls f for each live triangle t incident to the vertex a, with k
+// the position of a in t, turning counterclockwise, until f returns false.
+func (tr *Triangulation) around(a int, f func(t, k int) bool) {
+	start := tr.vtri[a]
+	t := start
+	for {
+		k := 0
+		for tr.tris[t].v[k] != a {
+			k++
+		}
+		if !f(t, k) {
+			return
+		}
+		t = tr.tris[t].n[(k+1)%3]
+		if t == start {
+			return
+		}
+	}
+}
+
+// findEdge returns the triangle and edge index of the edge ab, or -1.
+func (tr *Triangulation) findEdge(a, b int) (int, int) {
+	et, ei := -1, -1
+	tr.around(a, func(t, k int) bool {
+		switch b {
+		case tr.tris[t].v[(k+1)%3]:
+			et, ei = t, (k+2)%3
+		case tr.tris[t].v[(k+2)%3]:
+			et, ei = t, (k+1)%3
+		default:
+			return true
+		}
+		return false
+	})
+	return et, ei
+}
+
+// setConstrained marks the existing edge ab as a constrained segment.
+func (tr *Triangulation) setConstrained(a, b int) {
+	t, i := tr.findEdge(a, b)
+	u := tr.tris[t].n[i]
+	tr.tris[t].c[i] = true
+	tr.tris[u].c[tr.tris[u].edgeTo(t)] = true
+}
+
+// validVertex reports whether v is a vertex of the triangulation.
+func (tr *Triangulation) validVertex(v int) bool {
+	return v >= 0 && v < len(tr.points) && tr.vtri[v] >= 0 && tr.tris[tr.vtri[v]].v[0] != dead
+}
+
+// InsertSegment makes the segment between the vertices a and b an edge of
+// the triangulation and marks it as constrained. The triangles it crosses
+// are removed and the cavities on both sides of it are retriangulated so
+// that the result is the constrained Delaunay triangulation. A segment
+// passing exactly through other vertices is split at them.
+func (tr *Triangulation) InsertSegment(a, b int) error {
+	if len(tr.tris) == 0 {
+		return ErrFlat
+	}
+	if !tr.validVertex(a) || !tr.validVertex(b) {
+		return ErrVertex
+	}
+	for a != b {
+		next, err := tr.insertSubsegment(a, b)
+		if err != nil {
+			return err
+		}
+		a = next
+	}
+	return nil
+}
+
+// insertSubsegment inserts the part of the segment ab from a up to b or to
+// the first vertex lying on it, and returns that vertex.
+func (tr *Triangulation) insertSubsegment(a, b int) (int, error) {
+	pa, pb := tr.points[a], tr.points[b]
+
+	// find the edge out of a along ab, or the triangle at a that ab leaves
+	// through its opposite edge
+	end, first := -1, -1
+	tr.around(a, func(t, k int) bool {
+		x, y := tr.tris[t].v[(k+1)%3], tr.tris[t].v[(k+2)%3]
+		if x == ghost {
+			return true
+		}
+		if x == b {
+			end = b
+			return false
+		}
+		ox := predicates.Orient2d(pa, pb, tr.points[x])
+		if ox == 0 && between(pa, pb, tr.points[x]) {
+			end = x
+			return false
+		}
+		if y != ghost && ox < 0 && predicates.Orient2d(pa, pb, tr.points[y]) > 0 {
+			first = t
+			return false
+		}
+		return true
+	})
+	if end >= 0 {
+		tr.setConstrained(a, end)
+		return end, nil
+	}
+
+	// walk along ab collecting the crossed triangles and the vertices on
+	// each side of it, in order from a
+	tr.stamp++
+	crossed := []int{first}
+	tr.mark[first] = tr.stamp
+	var left, right []int
+	t := first
+	k := 0
+	for tr.tris[t].v[k] != a {
+		k++
+	}
+	for {
+		// the edge of t crossed by ab runs from u (right) to w (left) and
+		// is opposite k
+		u, w := tr.tris[t].v[(k+1)%3], tr.tris[t].v[(k+2)%3]
+		if tr.tris[t].c[k] {
+			return a, ErrCrossing
+		}
+		if len(right) == 0 || right[len(right)-1] != u {
+			right = append(right, u)
+		}
+		if len(left) == 0 || left[len(left)-1] != w {
+			left = append(left, w)
+		}
+		next := tr.tris[t].n[k]
+		crossed = append(crossed, next)
+		tr.mark[next] = tr.stamp
+		j := tr.tris[next].edgeTo(t)
+		z := tr.tris[next].v[j]
+		if z == b {
+			end = b
+			break
+		}
+		oz := predicates.Orient2d(pa, pb, tr.points[z])
+		if oz == 0 {
+			end = z
+			break
+		}
+		t = next
+		if oz < 0 {
+			// z is right of ab, the next crossed edge is z to w, opposite u
+			k = (j + 2) % 3
+		} else {
+			k = (j + 1) % 3
+		}
+	}
+
+	// remember the edges of the cavity boundary, with the triangles outside
+	// them and their constraint flags
+	type outside struct {
+		t, i int
+		c    bool
+	}
+	boundary := make(map[[2]int]outside)
+	for _, c := range crossed {
+		for i := 0; i < 3; i++ {
+			u := tr.tris[c].n[i]
+			if tr.mark[u] != tr.stamp {
+				x, y := tr.tris[c].v[(i+1)%3], tr.tris[c].v[(i+2)%3]
+				boundary[[2]int{x, y}] = outside{u, tr.tris[u].edgeTo(c), tr.tris[c].c[i]}
+			}
+		}
+	}
+	// the crossed triangles are all on the same side of any segment
+	out := tr.tris[first].out
+	for _, c := range crossed {
+		tr.freeTriangle(c)
+	}
+
+	var created []int
+	var fill func(p, q int, chain []int)
+	fill = func(p, q int, chain []int) {
+		if len(chain) == 0 {
+			return
+		}
+		// the vertex of the chain whose circle through p and q holds no
+		// other vertex of the chain
+		m := 0
+		for i := 1; i < len(chain); i++ {
+			if predicates.Incircle(tr.points[p], tr.points[q], tr.points[chain[m]], tr.points[chain[i]]) > 0 {
+				m = i
+			}
+		}
+		nt := tr.newTriangle(p, q, chain[m])
+		tr.tris[nt].out = out
+		created = append(created, nt)
+		fill(p, chain[m], chain[:m])
+		fill(chain[m], q, chain[m+1:])
+	}
+	fill(a, end, left)
+	reversed := make([]int, len(right))
+	for i, v := range right {
+		reversed[len(right)-1-i] = v
+	}
+	fill(end, a, reversed)
+
+	// link the new triangles to each other and to the outside
+	inner := make(map[[2]int][2]int)
+	for _, nt := range created {
+		for i := 0; i < 3; i++ {
+			x, y := tr.tris[nt].v[(i+1)%3], tr.tris[nt].v[(i+2)%3]
+			if o, ok := boundary[[2]int{x, y}]; ok {
+				tr.tris[nt].n[i] = o.t
+				tr.tris[nt].c[i] = o.c
+				tr.tris[o.t].n[o.i] = nt
+				continue
+			}
+			if o, ok := inner[[2]int{y, x}]; ok {
+				tr.tris[nt].n[i] = o[0]
+				tr.tris[o[0]].n[o[1]] = nt
+				delete(inner, [2]int{y, x})
+			} else {
+				inner[[2]int{x, y}] = [2]int{nt, i}
+			}
+		}
+	}
+	tr.last = created[0]
+	tr.setConstrained(a, end)
+	return end, nil
+}
+
+// Segments returns the constrained segments of the triangulation as pairs of
+// vertex indices.
+func (tr *Triangulation) Segments() [][2]int {
+	var segs [][2]int
+	for t := range tr.tris {
+		tri := &tr.tris[t]
+		if tri.v[0] == dead {
+			continue
+		}
+		for i, c := range tri.c {
+			// each segment is seen from both sides, report it from the
+			// side where it runs forward
+			a, b := tri.v[(i+1)%3], tri.v[(i+2)%3]
+			if c && a >= 0 && a < b {
+				segs = append(segs, [2]int{a, b})
+			}
+		}
+	}
+	return segs
+}
+
+// Encroached reports whether a vertex opposite the edge ab lies inside its
+// diametral circle, which Incircle2p decides. ab must be an edge.
+func (tr *Triangulation) Encroached(a, b int) bool {
+	t, i := tr.findEdge(a, b)
+	if t < 0 {
+		return false
+	}
+	pa, pb := tr.points[a], tr.points[b]
+	for _, s := range [2]int{t, tr.tris[t].n[i]} {
+		for _, v := range tr.tris[s].v {
+			if v != a && v != b && v != ghost && predicates.Incircle2p(pa, pb, tr.points[v]) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
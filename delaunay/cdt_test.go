@@ -0,0 +1,136 @@
+package delaunay
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// hasSegment reports whether ab is a constrained edge of m.
+func hasSegment(m *Mesh, a, b int) bool {
+	for i, tri := range m.Triangles {
+		for e := 0; e < 3; e++ {
+			x, y := tri[(e+1)%3], tri[(e+2)%3]
+			if m.Constrained[i][e] && (x == a && y == b || x == b && y == a) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestInsertSegment(t *testing.T) {
+	// a floorplan: an outer wall, an inner wall and scattered points that
+	// the walls cut through
+	points := [][2]Float{{0, 0}, {10, 0}, {10, 6}, {0, 6}, {2, 3}, {8, 3}}
+	r := rand.New(rand.NewSource(3))
+	for i := 0; i < 200; i++ {
+		points = append(points, [2]Float{Float(r.Float64() * 10), Float(r.Float64() * 6)})
+	}
+	segments := [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}, {4, 5}}
+	tr, err := NewConstrained(points, segments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := tr.Mesh()
+	checkMesh(t, m)
+	for _, s := range segments {
+		if !hasSegment(m, s[0], s[1]) {
+			t.Errorf("segment %v is missing", s)
+		}
+	}
+	if got := len(tr.Segments()); got != len(segments) {
+		t.Errorf("%d segments, want %d", got, len(segments))
+	}
+}
+
+func TestInsertSegmentThroughVertices(t *testing.T) {
+	// the diagonal of a grid passes exactly through its vertices
+	var points [][2]Float
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			points = append(points, [2]Float{Float(i), Float(j)})
+		}
+	}
+	_, err := NewConstrained(points, [][2]int{{0, 63}, {7, 56}})
+	if err != ErrCrossing {
+		t.Fatal("crossing diagonals must fail, they do not meet at a vertex")
+	}
+	// the second segment meets the diagonal at the vertex (4, 4) and passes
+	// through (2, 3) on its way
+	tr, err := NewConstrained(points, [][2]int{{0, 63}, {2, 53}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := tr.Mesh()
+	checkMesh(t, m)
+	for i := 0; i < 7; i++ {
+		if !hasSegment(m, 9*i, 9*i+9) {
+			t.Errorf("segment %v is missing", [2]int{9 * i, 9*i + 9})
+		}
+	}
+	if !hasSegment(m, 2, 19) || !hasSegment(m, 19, 36) || !hasSegment(m, 36, 53) {
+		t.Errorf("segment through (2, 3) and (4, 4) is not split there")
+	}
+}
+
+func TestInsertSegmentRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	for round := 0; round < 20; round++ {
+		points := make([][2]Float, 100)
+		for i := range points {
+			points[i] = [2]Float{Float(r.Intn(50)), Float(r.Intn(50))}
+		}
+		tr := New(points)
+		var want [][2]int
+		for i := 0; i < 30; i++ {
+			a, b := r.Intn(len(points)), r.Intn(len(points))
+			if err := tr.InsertSegment(a, b); err == nil {
+				want = append(want, [2]int{a, b})
+			} else if err != ErrCrossing && err != ErrVertex {
+				t.Fatal(err)
+			}
+		}
+		m := tr.Mesh()
+		checkMesh(t, m)
+		for _, s := range want {
+			if s[0] == s[1] || points[s[0]] == points[s[1]] {
+				continue
+			}
+			// the segment may have been split at collinear vertices,
+			// check its end at a
+			found := false
+			for i, tri := range m.Triangles {
+				for e := 0; e < 3; e++ {
+					x := tri[(e+1)%3]
+					y := tri[(e+2)%3]
+					if m.Constrained[i][e] && (m.Points[x] == points[s[0]] || m.Points[y] == points[s[0]]) {
+						found = true
+					}
+				}
+			}
+			if !found {
+				t.Errorf("segment %v is missing", s)
+			}
+		}
+	}
+}
+
+func TestInsertOnSegment(t *testing.T) {
+	points := [][2]Float{{0, 0}, {8, 0}, {4, 4}, {4, -4}, {2, 1}, {6, -1}}
+	tr, err := NewConstrained(points, [][2]int{{0, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := tr.Insert([2]Float{3, 0})
+	w := tr.Insert([2]Float{6, 0})
+	// a point near the segment must not be connected across it
+	tr.Insert([2]Float{5, 0.01})
+	m := tr.Mesh()
+	checkMesh(t, m)
+	if !hasSegment(m, 0, v) || !hasSegment(m, v, w) || !hasSegment(m, w, 1) {
+		t.Errorf("segment is not split at the inserted points: %v", tr.Segments())
+	}
+	if hasSegment(m, 0, 1) {
+		t.Errorf("split segment is still present")
+	}
+}
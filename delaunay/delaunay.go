@@ -0,0 +1,511 @@
+// Package delaunay builds Delaunay triangulations on top of the exact
+// predicates of package predicates. Every combinatorial decision is taken
+// with Orient2d, Incircle or exact coordinate comparisons, so the
+// construction never fails on degenerate input such as collinear,
+// cocircular or duplicated points.
+package delaunay
+
+import (
+	"sort"
+
+	"github.com/toy80/predicates"
+)
+
+// Float is the floating-point type of the coordinates.
+type Float = predicates.Float
+
+// ghost is the vertex at infinity. The triangles sharing it (ghost
+// triangles) close the triangulation around its convex hull, so that
+// insertion outside the hull is no different from insertion inside it.
+const ghost = -1
+
+// dead marks the vertices of a triangle slot on the free list.
+const dead = -2
+
+// triangle is a counterclockwise triangle. Edge i is opposite v[i], running
+// from v[(i+1)%3] to v[(i+2)%3], n[i] is the triangle on the other side of
+// it, and c[i] tells whether it is a constrained segment. The flag is kept
+// on both sides of the edge. out marks the triangles that Refine found
+// outside the domain or in a hole; new triangles inherit it from the ones
+// they replace.
+type triangle struct {
+	v   [3]int
+	n   [3]int
+	c   [3]bool
+	out bool
+}
+
+// ghostIndex returns the position of the ghost vertex in t, or -1.
+func (t *triangle) ghostIndex() int {
+	for i, v := range t.v {
+		if v == ghost {
+			return i
+		}
+	}
+	return -1
+}
+
+// edgeTo returns the index of the edge of t shared with triangle u.
+func (t *triangle) edgeTo(u int) int {
+	for i, n := range t.n {
+		if n == u {
+			return i
+		}
+	}
+	panic("delaunay: triangles are not adjacent")
+}
+
+// Triangulation is an incremental Delaunay triangulation of a set of points.
+// While all the points are collinear it has no triangles.
+type Triangulation struct {
+	points [][2]Float
+	tris   []triangle
+	free   []int
+	vtri   []int // a triangle incident to each vertex, -1 if the vertex is unused
+
+	pending []int // vertices inserted while the points are all collinear
+	last    int   // where the next point location starts
+
+	// scratch space for insertion
+	mark    []int
+	stamp   int
+	cavity  []int
+	created []int
+	start   []int // new triangle starting at a vertex, indexed by vertex+1
+
+	seed uint32
+}
+
+// Mesh is a snapshot of the finite triangles of a triangulation.
+type Mesh struct {
+	Points [][2]Float
+	// Triangles are counterclockwise triples of indices into Points.
+	Triangles [][3]int
+	// Neighbors[t][i] is the triangle across the edge opposite
+	// Triangles[t][i], or -1 on the convex hull, or on the boundary of the
+	// domain of a refined triangulation.
+	Neighbors [][3]int
+	// Constrained[t][i] tells whether the edge opposite Triangles[t][i] is
+	// a constrained segment.
+	Constrained [][3]bool
+}
+
+// New returns the Delaunay triangulation of points. Duplicated points are
+// inserted once, the copies are left out of the triangulation.
+func New(points [][2]Float) *Triangulation {
+	tr := &Triangulation{seed: 2463534242}
+	tr.points = make([][2]Float, 0, len(points))
+	tr.vtri = make([]int, 0, len(points))
+	tr.tris = make([]triangle, 0, 2*len(points)+4)
+	for _, p := range points {
+		tr.points = append(tr.points, p)
+		tr.vtri = append(tr.vtri, -1)
+	}
+	// consecutive points close to each other keep the walks of point
+	// location short
+	for _, v := range hilbertOrder(points) {
+		tr.insertVertex(v)
+	}
+	return tr
+}
+
+// hilbertOrder returns the indices of points sorted along a Hilbert curve
+// laid over their bounding box.
+func hilbertOrder(points [][2]Float) []int {
+	order := make([]int, len(points))
+	if len(points) == 0 {
+		return order
+	}
+	lo, hi := points[0], points[0]
+	for _, p := range points {
+		for k := 0; k < 2; k++ {
+			if p[k] < lo[k] {
+				lo[k] = p[k]
+			}
+			if p[k] > hi[k] {
+				hi[k] = p[k]
+			}
+		}
+	}
+	const side = 1 << 16
+	scale := [2]float64{float64(side-1) / (float64(hi[0]) - float64(lo[0])), float64(side-1) / (float64(hi[1]) - float64(lo[1]))}
+	keys := make([]uint64, len(points))
+	for i, p := range points {
+		var c [2]uint32
+		for k := 0; k < 2; k++ {
+			if hi[k] > lo[k] {
+				c[k] = uint32((float64(p[k]) - float64(lo[k])) * scale[k])
+			}
+		}
+		keys[i] = hilbertIndex(c[0], c[1], side)
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return keys[order[i]] < keys[order[j]] })
+	return order
+}
+
+// hilbertIndex returns the distance of (x, y) along the Hilbert curve filling
+// a square of the given side, which must be a power of two.
+func hilbertIndex(x, y, side uint32) uint64 {
+	var d uint64
+	for s := side / 2; s > 0; s /= 2 {
+		var rx, ry uint32
+		if x&s != 0 {
+			rx = 1
+		}
+		if y&s != 0 {
+			ry = 1
+		}
+		d += uint64(s) * uint64(s) * uint64((3*rx)^ry)
+		if ry == 0 {
+			if rx == 1 {
+				x = s - 1 - x
+				y = s - 1 - y
+			}
+			x, y = y, x
+		}
+	}
+	return d
+}
+
+// random is a xorshift generator, deterministic so that triangulations are
+// reproducible.
+func (tr *Triangulation) random() uint32 {
+	x := tr.seed
+	x ^= x << 13
+	x ^= x >> 17
+	x ^= x << 5
+	tr.seed = x
+	return x
+}
+
+// Points returns the points of the triangulation, indexed by vertex.
+func (tr *Triangulation) Points() [][2]Float {
+	return tr.points
+}
+
+// Insert adds p to the triangulation and returns its vertex index. If p is
+// already a vertex, its existing index is returned.
+func (tr *Triangulation) Insert(p [2]Float) int {
+	v := len(tr.points)
+	tr.points = append(tr.points, p)
+	tr.vtri = append(tr.vtri, -1)
+	if u := tr.insertVertex(v); u != v {
+		tr.points = tr.points[:v]
+		tr.vtri = tr.vtri[:v]
+		return u
+	}
+	return v
+}
+
+// Mesh returns the finite triangles of the triangulation, except the ones
+// that Refine left outside its domain.
+func (tr *Triangulation) Mesh() *Mesh {
+	m := &Mesh{Points: tr.points}
+	index := make([]int, len(tr.tris))
+	for t := range tr.tris {
+		index[t] = -1
+		if tr.isFinite(t) && !tr.tris[t].out {
+			index[t] = len(m.Triangles)
+			m.Triangles = append(m.Triangles, tr.tris[t].v)
+		}
+	}
+	m.Neighbors = make([][3]int, len(m.Triangles))
+	m.Constrained = make([][3]bool, len(m.Triangles))
+	for t := range tr.tris {
+		if k := index[t]; k >= 0 {
+			for i, u := range tr.tris[t].n {
+				m.Neighbors[k][i] = index[u]
+			}
+			m.Constrained[k] = tr.tris[t].c
+		}
+	}
+	return m
+}
+
+// isFinite reports whether t is a live triangle without the ghost vertex.
+func (tr *Triangulation) isFinite(t int) bool {
+	v := tr.tris[t].v
+	return v[0] >= 0 && v[1] >= 0 && v[2] >= 0
+}
+
+// newTriangle allocates a triangle, reusing a free slot if there is one.
+func (tr *Triangulation) newTriangle(a, b, c int) int {
+	var t int
+	if n := len(tr.free); n > 0 {
+		t = tr.free[n-1]
+		tr.free = tr.free[:n-1]
+		tr.tris[t] = triangle{v: [3]int{a, b, c}, n: [3]int{-1, -1, -1}}
+	} else {
+		t = len(tr.tris)
+		tr.tris = append(tr.tris, triangle{v: [3]int{a, b, c}, n: [3]int{-1, -1, -1}})
+		tr.mark = append(tr.mark, 0)
+	}
+	for _, v := range [3]int{a, b, c} {
+		if v >= 0 {
+			tr.vtri[v] = t
+		}
+	}
+	return t
+}
+
+// freeTriangle returns t to the free list.
+func (tr *Triangulation) freeTriangle(t int) {
+	tr.tris[t].v = [3]int{dead, dead, dead}
+	tr.free = append(tr.free, t)
+}
+
+// insertVertex inserts the vertex v whose point is already in tr.points. It
+// returns v, or the vertex already at the same position.
+func (tr *Triangulation) insertVertex(v int) int {
+	if len(tr.tris) == 0 {
+		return tr.insertCollinear(v)
+	}
+	p := tr.points[v]
+	t := tr.locate(p, tr.last)
+	for _, u := range tr.tris[t].v {
+		if u != ghost && tr.points[u] == p {
+			return u
+		}
+	}
+	// the cavity never grows across a constrained segment, unless p lies on
+	// it and splits it in two
+	split := -1
+	if tr.isFinite(t) {
+		for i, c := range tr.tris[t].c {
+			a, b := tr.tris[t].v[(i+1)%3], tr.tris[t].v[(i+2)%3]
+			if c && predicates.Orient2d(tr.points[a], tr.points[b], p) == 0 {
+				split = i
+			}
+		}
+	}
+	if split < 0 {
+		tr.digCavity(t, p, tr.isConstrained)
+		tr.fillCavity(v)
+		return v
+	}
+	a, b := tr.tris[t].v[(split+1)%3], tr.tris[t].v[(split+2)%3]
+	tr.digCavity(t, p, func(c, i int) bool {
+		if !tr.tris[c].c[i] {
+			return false
+		}
+		x, y := tr.tris[c].v[(i+1)%3], tr.tris[c].v[(i+2)%3]
+		return !(x == a && y == b || x == b && y == a)
+	})
+	tr.fillCavity(v)
+	tr.setConstrained(a, v)
+	tr.setConstrained(v, b)
+	return v
+}
+
+// isConstrained reports whether edge i of triangle t is a constrained
+// segment.
+func (tr *Triangulation) isConstrained(t, i int) bool {
+	return tr.tris[t].c[i]
+}
+
+// insertCollinear handles the points inserted before the first triangle
+// exists, building it as soon as a point off the common line arrives.
+func (tr *Triangulation) insertCollinear(v int) int {
+	p := tr.points[v]
+	for _, u := range tr.pending {
+		if tr.points[u] == p {
+			return u
+		}
+	}
+	if len(tr.pending) < 2 {
+		tr.pending = append(tr.pending, v)
+		return v
+	}
+	a, b := tr.pending[0], tr.pending[1]
+	o := predicates.Orient2d(tr.points[a], tr.points[b], p)
+	if o == 0 {
+		tr.pending = append(tr.pending, v)
+		return v
+	}
+	if o < 0 {
+		a, b = b, a
+	}
+	tr.initTriangle(a, b, v)
+	rest := tr.pending[2:]
+	tr.pending = nil
+	for _, u := range rest {
+		tr.insertVertex(u)
+	}
+	return v
+}
+
+// initTriangle builds the counterclockwise triangle abc and the three ghost
+// triangles around it.
+func (tr *Triangulation) initTriangle(a, b, c int) {
+	t := tr.newTriangle(a, b, c)
+	g0 := tr.newTriangle(c, b, ghost)
+	g1 := tr.newTriangle(a, c, ghost)
+	g2 := tr.newTriangle(b, a, ghost)
+	tr.tris[t].n = [3]int{g0, g1, g2}
+	tr.tris[g0].n = [3]int{g2, g1, t}
+	tr.tris[g1].n = [3]int{g0, g2, t}
+	tr.tris[g2].n = [3]int{g1, g0, t}
+	tr.last = t
+}
+
+// locate returns a triangle that contains p in its closure, or a ghost
+// triangle whose hull edge has p strictly on its outer side. It walks from
+// start, crossing an edge that separates the current triangle from p chosen
+// at random, which terminates on any triangulation with probability one.
+func (tr *Triangulation) locate(p [2]Float, start int) int {
+	t := start
+	if t < 0 || t >= len(tr.tris) || tr.tris[t].v[0] == dead {
+		t = tr.anyTriangle()
+	}
+	if k := tr.tris[t].ghostIndex(); k >= 0 {
+		t = tr.tris[t].n[k]
+	}
+	limit := 4*len(tr.tris) + 64
+	for step := 0; step < limit; step++ {
+		tri := &tr.tris[t]
+		if tri.ghostIndex() >= 0 {
+			return t
+		}
+		r := int(tr.random() % 3)
+		next := -1
+		for j := 0; j < 3; j++ {
+			i := (r + j) % 3
+			a, b := tri.v[(i+1)%3], tri.v[(i+2)%3]
+			if predicates.Orient2d(tr.points[a], tr.points[b], p) < 0 {
+				next = tri.n[i]
+				break
+			}
+		}
+		if next < 0 {
+			return t
+		}
+		t = next
+	}
+	return tr.locateBrute(p)
+}
+
+// anyTriangle returns a live triangle.
+func (tr *Triangulation) anyTriangle() int {
+	for t := range tr.tris {
+		if tr.tris[t].v[0] != dead {
+			return t
+		}
+	}
+	panic("delaunay: empty triangulation")
+}
+
+// locateBrute is the exhaustive fallback of locate.
+func (tr *Triangulation) locateBrute(p [2]Float) int {
+	for t := range tr.tris {
+		if !tr.isFinite(t) {
+			continue
+		}
+		v := tr.tris[t].v
+		if predicates.PointInTriangle(tr.points[v[0]], tr.points[v[1]], tr.points[v[2]], p) != predicates.Outside {
+			return t
+		}
+	}
+	for t := range tr.tris {
+		tri := &tr.tris[t]
+		if k := tri.ghostIndex(); k >= 0 {
+			a, b := tri.v[(k+1)%3], tri.v[(k+2)%3]
+			if predicates.Orient2d(tr.points[a], tr.points[b], p) > 0 {
+				return t
+			}
+		}
+	}
+	panic("delaunay: point location failed")
+}
+
+// between reports whether p lies strictly between the distinct points a and
+// b, given that the three points are collinear.
+func between(a, b, p [2]Float) bool {
+	if lessXY(b, a) {
+		a, b = b, a
+	}
+	return lessXY(a, p) && lessXY(p, b)
+}
+
+func lessXY(a, b [2]Float) bool {
+	return a[0] < b[0] || a[0] == b[0] && a[1] < b[1]
+}
+
+// inConflict reports whether p lies strictly inside the circumcircle of t.
+// For a ghost triangle the circumcircle degenerates to the open half-plane
+// outside its hull edge, together with the open edge itself.
+func (tr *Triangulation) inConflict(t int, p [2]Float) bool {
+	tri := &tr.tris[t]
+	k := tri.ghostIndex()
+	if k < 0 {
+		v := tri.v
+		return predicates.Incircle(tr.points[v[0]], tr.points[v[1]], tr.points[v[2]], p) > 0
+	}
+	a, b := tr.points[tri.v[(k+1)%3]], tr.points[tri.v[(k+2)%3]]
+	o := predicates.Orient2d(a, b, p)
+	if o != 0 {
+		return o > 0
+	}
+	return between(a, b, p)
+}
+
+// digCavity collects in tr.cavity the triangles in conflict with p that are
+// connected to t, which must be in conflict itself. blocked, if not nil,
+// reports edges the cavity must not grow across.
+func (tr *Triangulation) digCavity(t int, p [2]Float, blocked func(t, i int) bool) {
+	tr.stamp++
+	tr.cavity = append(tr.cavity[:0], t)
+	tr.mark[t] = tr.stamp
+	for k := 0; k < len(tr.cavity); k++ {
+		c := tr.cavity[k]
+		for i, u := range tr.tris[c].n {
+			if tr.mark[u] == tr.stamp || blocked != nil && blocked(c, i) {
+				continue
+			}
+			if tr.inConflict(u, p) {
+				tr.mark[u] = tr.stamp
+				tr.cavity = append(tr.cavity, u)
+			}
+		}
+	}
+}
+
+// fillCavity replaces the triangles in tr.cavity by a fan of triangles
+// joining the cavity boundary to v. It returns the new triangles, which are
+// also left in tr.created.
+func (tr *Triangulation) fillCavity(v int) []int {
+	if need := len(tr.points) + 1; len(tr.start) < need {
+		tr.start = append(tr.start, make([]int, need-len(tr.start))...)
+	}
+	tr.created = tr.created[:0]
+	for _, c := range tr.cavity {
+		for i := 0; i < 3; i++ {
+			u := tr.tris[c].n[i]
+			if tr.mark[u] == tr.stamp {
+				continue
+			}
+			a, b := tr.tris[c].v[(i+1)%3], tr.tris[c].v[(i+2)%3]
+			nt := tr.newTriangle(a, b, v)
+			tr.tris[nt].n[2] = u
+			tr.tris[nt].c[2] = tr.tris[c].c[i]
+			tr.tris[nt].out = tr.tris[c].out
+			tr.tris[u].n[tr.tris[u].edgeTo(c)] = nt
+			tr.start[a+1] = nt
+			tr.created = append(tr.created, nt)
+		}
+	}
+	// the new triangles form a fan around v, triangle (a, b, v) meets
+	// (b, x, v) across the edge bv and (y, a, v) across the edge va
+	for _, nt := range tr.created {
+		b := tr.tris[nt].v[1]
+		next := tr.start[b+1]
+		tr.tris[nt].n[0] = next
+		tr.tris[next].n[1] = nt
+	}
+	for _, c := range tr.cavity {
+		tr.freeTriangle(c)
+	}
+	tr.vtri[v] = tr.created[0]
+	tr.last = tr.created[0]
+	return tr.created
+}
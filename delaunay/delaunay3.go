@@ -0,0 +1,487 @@
+package delaunay
+
+import (
+	"sort"
+
+	"github.com/toy80/predicates"
+)
+
+// tetrahedron is a positively oriented tetrahedron, as defined by
+// predicates.Orient3d. Face i is opposite v[i] and n[i] is the tetrahedron
+// on the other side of it.
+type tetrahedron struct {
+	v [4]int
+	n [4]int
+}
+
+// ghostIndex returns the position of the ghost vertex in t, or -1.
+func (t *tetrahedron) ghostIndex() int {
+	for i, v := range t.v {
+		if v == ghost {
+			return i
+		}
+	}
+	return -1
+}
+
+// faceTo returns the index of the face of t shared with tetrahedron u.
+func (t *tetrahedron) faceTo(u int) int {
+	for i, n := range t.n {
+		if n == u {
+			return i
+		}
+	}
+	panic("delaunay: tetrahedra are not adjacent")
+}
+
+// Tetrahedralization is an incremental 3D Delaunay tetrahedralization of a
+// set of points. While all the points are coplanar it has no tetrahedra.
+//
+// Cospherical and coplanar points, such as those of a regular lattice, are
+// handled exactly: a point is only inserted into the tetrahedra whose
+// circumsphere strictly contains it, which always yields a valid Delaunay
+// tetrahedralization without flat or missing tetrahedra.
+type Tetrahedralization struct {
+	points [][3]Float
+	tets   []tetrahedron
+	free   []int
+
+	pending []int // vertices inserted while the points are all coplanar
+	last    int   // where the next point location starts
+
+	// scratch space for insertion
+	mark    []int
+	stamp   int
+	cavity  []int
+	created []int
+	edges   map[[2]int][2]int
+
+	seed uint32
+}
+
+// Mesh3 is a snapshot of the finite tetrahedra of a tetrahedralization.
+type Mesh3 struct {
+	Points [][3]Float
+	// Tetrahedra are positively oriented quadruples of indices into Points.
+	Tetrahedra [][4]int
+	// Neighbors[t][i] is the tetrahedron across the face opposite
+	// Tetrahedra[t][i], or -1 on the convex hull.
+	Neighbors [][4]int
+}
+
+// NewTetrahedralization returns the Delaunay tetrahedralization of points.
+// Duplicated points are inserted once, the copies are left out.
+func NewTetrahedralization(points [][3]Float) *Tetrahedralization {
+	tr := &Tetrahedralization{seed: 2463534242, edges: make(map[[2]int][2]int)}
+	tr.points = append(make([][3]Float, 0, len(points)), points...)
+	tr.tets = make([]tetrahedron, 0, 7*len(points)+5)
+	for _, v := range mortonOrder(points) {
+		tr.insertVertex(v)
+	}
+	return tr
+}
+
+// mortonOrder returns the indices of points sorted along a Z-order curve
+// laid over their bounding box.
+func mortonOrder(points [][3]Float) []int {
+	order := make([]int, len(points))
+	if len(points) == 0 {
+		return order
+	}
+	lo, hi := points[0], points[0]
+	for _, p := range points {
+		for k := 0; k < 3; k++ {
+			if p[k] < lo[k] {
+				lo[k] = p[k]
+			}
+			if p[k] > hi[k] {
+				hi[k] = p[k]
+			}
+		}
+	}
+	const side = 1 << 20
+	keys := make([]uint64, len(points))
+	for i, p := range points {
+		var key uint64
+		for k := 0; k < 3; k++ {
+			var c uint64
+			if hi[k] > lo[k] {
+				c = uint64((float64(p[k]) - float64(lo[k])) / (float64(hi[k]) - float64(lo[k])) * (side - 1))
+			}
+			for b := 0; b < 20; b++ {
+				key |= (c >> uint(b) & 1) << uint(3*b+k)
+			}
+		}
+		keys[i] = key
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return keys[order[i]] < keys[order[j]] })
+	return order
+}
+
+func (tr *Tetrahedralization) random() uint32 {
+	x := tr.seed
+	x ^= x << 13
+	x ^= x >> 17
+	x ^= x << 5
+	tr.seed = x
+	return x
+}
+
+// Points returns the points of the tetrahedralization, indexed by vertex.
+func (tr *Tetrahedralization) Points() [][3]Float {
+	return tr.points
+}
+
+// Insert adds p to the tetrahedralization and returns its vertex index. If p
+// is already a vertex, its existing index is returned.
+func (tr *Tetrahedralization) Insert(p [3]Float) int {
+	v := len(tr.points)
+	tr.points = append(tr.points, p)
+	if u := tr.insertVertex(v); u != v {
+		tr.points = tr.points[:v]
+		return u
+	}
+	return v
+}
+
+// Mesh returns the finite tetrahedra of the tetrahedralization.
+func (tr *Tetrahedralization) Mesh() *Mesh3 {
+	m := &Mesh3{Points: tr.points}
+	index := make([]int, len(tr.tets))
+	for t := range tr.tets {
+		index[t] = -1
+		if tr.isFinite(t) {
+			index[t] = len(m.Tetrahedra)
+			m.Tetrahedra = append(m.Tetrahedra, tr.tets[t].v)
+		}
+	}
+	m.Neighbors = make([][4]int, len(m.Tetrahedra))
+	for t := range tr.tets {
+		if k := index[t]; k >= 0 {
+			for i, u := range tr.tets[t].n {
+				m.Neighbors[k][i] = index[u]
+			}
+		}
+	}
+	return m
+}
+
+// isFinite reports whether t is a live tetrahedron without the ghost vertex.
+func (tr *Tetrahedralization) isFinite(t int) bool {
+	v := tr.tets[t].v
+	return v[0] >= 0 && v[1] >= 0 && v[2] >= 0 && v[3] >= 0
+}
+
+// newTetrahedron allocates a tetrahedron, reusing a free slot if there is one.
+func (tr *Tetrahedralization) newTetrahedron(v [4]int) int {
+	tet := tetrahedron{v: v, n: [4]int{-1, -1, -1, -1}}
+	if n := len(tr.free); n > 0 {
+		t := tr.free[n-1]
+		tr.free = tr.free[:n-1]
+		tr.tets[t] = tet
+		return t
+	}
+	tr.tets = append(tr.tets, tet)
+	tr.mark = append(tr.mark, 0)
+	return len(tr.tets) - 1
+}
+
+func (tr *Tetrahedralization) freeTetrahedron(t int) {
+	tr.tets[t].v = [4]int{dead, dead, dead, dead}
+	tr.free = append(tr.free, t)
+}
+
+// orient returns Orient3d of the finite tetrahedron v with v[i] replaced by
+// p.
+func (tr *Tetrahedralization) orient(v [4]int, i int, p [3]Float) Float {
+	var q [4][3]Float
+	for j := 0; j < 4; j++ {
+		if j == i {
+			q[j] = p
+		} else {
+			q[j] = tr.points[v[j]]
+		}
+	}
+	return predicates.Orient3d(q[0], q[1], q[2], q[3])
+}
+
+func (tr *Tetrahedralization) insertVertex(v int) int {
+	if len(tr.tets) == 0 {
+		return tr.insertCoplanar(v)
+	}
+	p := tr.points[v]
+	t := tr.locate(p, tr.last)
+	for _, u := range tr.tets[t].v {
+		if u != ghost && tr.points[u] == p {
+			return u
+		}
+	}
+	tr.digCavity(t, p)
+	tr.fillCavity(v)
+	return v
+}
+
+// insertCoplanar handles the points inserted before the first tetrahedron
+// exists, building it as soon as a point off the common plane arrives.
+func (tr *Tetrahedralization) insertCoplanar(v int) int {
+	p := tr.points[v]
+	for _, u := range tr.pending {
+		if tr.points[u] == p {
+			return u
+		}
+	}
+	// the first three pending vertices span the plane once there is a
+	// triangle, collinear points are kept behind them
+	switch {
+	case len(tr.pending) < 2:
+		tr.pending = append(tr.pending, v)
+		return v
+	case len(tr.pending) == 2 || predicates.Collinear3d(tr.points[tr.pending[0]], tr.points[tr.pending[1]], tr.points[tr.pending[2]]):
+		if !predicates.Collinear3d(tr.points[tr.pending[0]], tr.points[tr.pending[1]], p) {
+			// p and the first two span a plane, move it to the front
+			tr.pending = append(tr.pending, v)
+			k := len(tr.pending) - 1
+			tr.pending[2], tr.pending[k] = tr.pending[k], tr.pending[2]
+		} else {
+			tr.pending = append(tr.pending, v)
+		}
+		return v
+	}
+	a, b, c := tr.pending[0], tr.pending[1], tr.pending[2]
+	o := predicates.Orient3d(tr.points[a], tr.points[b], tr.points[c], p)
+	if o == 0 {
+		tr.pending = append(tr.pending, v)
+		return v
+	}
+	if o < 0 {
+		a, b = b, a
+	}
+	tr.initTetrahedron([4]int{a, b, c, v})
+	rest := tr.pending[3:]
+	tr.pending = nil
+	for _, u := range rest {
+		tr.insertVertex(u)
+	}
+	return v
+}
+
+// initTetrahedron builds the positively oriented tetrahedron v and the four
+// ghost tetrahedra around it.
+func (tr *Tetrahedralization) initTetrahedron(v [4]int) {
+	t := tr.newTetrahedron(v)
+	ts := []int{t}
+	for i := 0; i < 4; i++ {
+		// the ghost vertex takes the place of v[i] on the other side of
+		// face i, swapping two other vertices keeps the orientation
+		g := v
+		g[i] = ghost
+		j, k := (i+1)%4, (i+2)%4
+		g[j], g[k] = g[k], g[j]
+		ts = append(ts, tr.newTetrahedron(g))
+	}
+	tr.glue(ts)
+	tr.last = t
+}
+
+// glue links the faces shared by the tetrahedra ts.
+func (tr *Tetrahedralization) glue(ts []int) {
+	for x, s := range ts {
+		for _, u := range ts[x+1:] {
+			for i := 0; i < 4; i++ {
+				for j := 0; j < 4; j++ {
+					if sameFace(tr.tets[s].v, i, tr.tets[u].v, j) {
+						tr.tets[s].n[i] = u
+						tr.tets[u].n[j] = s
+					}
+				}
+			}
+		}
+	}
+}
+
+// sameFace reports whether face i of a and face j of b have the same
+// vertices.
+func sameFace(a [4]int, i int, b [4]int, j int) bool {
+	for k := 0; k < 4; k++ {
+		if k == i {
+			continue
+		}
+		found := false
+		for l := 0; l < 4; l++ {
+			if l != j && b[l] == a[k] {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// locate returns a tetrahedron that contains p in its closure, or a ghost
+// tetrahedron whose hull face has p strictly on its outer side. It is the
+// 3D counterpart of Triangulation.locate.
+func (tr *Tetrahedralization) locate(p [3]Float, start int) int {
+	t := start
+	if t < 0 || t >= len(tr.tets) || tr.tets[t].v[0] == dead {
+		t = tr.anyTetrahedron()
+	}
+	if k := tr.tets[t].ghostIndex(); k >= 0 {
+		t = tr.tets[t].n[k]
+	}
+	limit := 4*len(tr.tets) + 64
+	for step := 0; step < limit; step++ {
+		tet := &tr.tets[t]
+		if tet.ghostIndex() >= 0 {
+			return t
+		}
+		r := int(tr.random() % 4)
+		next := -1
+		for j := 0; j < 4; j++ {
+			i := (r + j) % 4
+			if tr.orient(tet.v, i, p) < 0 {
+				next = tet.n[i]
+				break
+			}
+		}
+		if next < 0 {
+			return t
+		}
+		t = next
+	}
+	return tr.locateBrute(p)
+}
+
+func (tr *Tetrahedralization) anyTetrahedron() int {
+	for t := range tr.tets {
+		if tr.tets[t].v[0] != dead {
+			return t
+		}
+	}
+	panic("delaunay: empty tetrahedralization")
+}
+
+// locateBrute is the exhaustive fallback of locate.
+func (tr *Tetrahedralization) locateBrute(p [3]Float) int {
+	for t := range tr.tets {
+		if !tr.isFinite(t) {
+			continue
+		}
+		v := tr.tets[t].v
+		inside := true
+		for i := 0; i < 4 && inside; i++ {
+			inside = tr.orient(v, i, p) >= 0
+		}
+		if inside {
+			return t
+		}
+	}
+	for t := range tr.tets {
+		if k := tr.tets[t].ghostIndex(); k >= 0 && tr.inConflict(t, p) {
+			return t
+		}
+	}
+	panic("delaunay: point location failed")
+}
+
+// inConflict reports whether p lies strictly inside the circumsphere of t.
+// For a ghost tetrahedron the circumsphere degenerates to the open
+// half-space outside its hull face, together with the open circumdisk of
+// the face, which is where the sphere of the finite tetrahedron on the
+// other side of the face cuts the plane.
+func (tr *Tetrahedralization) inConflict(t int, p [3]Float) bool {
+	tet := &tr.tets[t]
+	k := tet.ghostIndex()
+	if k < 0 {
+		v := tet.v
+		return predicates.Insphere(tr.points[v[0]], tr.points[v[1]], tr.points[v[2]], tr.points[v[3]], p) > 0
+	}
+	// the ghost vertex lies beyond the hull face, p is outside when
+	// putting it in the ghost's place keeps the orientation positive
+	var q [4][3]Float
+	for j := 0; j < 4; j++ {
+		if j == k {
+			q[j] = p
+		} else {
+			q[j] = tr.points[tet.v[j]]
+		}
+	}
+	o := predicates.Orient3d(q[0], q[1], q[2], q[3])
+	if o != 0 {
+		return o > 0
+	}
+	v := tr.tets[tet.n[k]].v
+	return predicates.Insphere(tr.points[v[0]], tr.points[v[1]], tr.points[v[2]], tr.points[v[3]], p) > 0
+}
+
+// digCavity collects in tr.cavity the tetrahedra in conflict with p that
+// are connected to t, which must be in conflict itself.
+func (tr *Tetrahedralization) digCavity(t int, p [3]Float) {
+	tr.stamp++
+	tr.cavity = append(tr.cavity[:0], t)
+	tr.mark[t] = tr.stamp
+	for k := 0; k < len(tr.cavity); k++ {
+		for _, u := range tr.tets[tr.cavity[k]].n {
+			if tr.mark[u] != tr.stamp && tr.inConflict(u, p) {
+				tr.mark[u] = tr.stamp
+				tr.cavity = append(tr.cavity, u)
+			}
+		}
+	}
+}
+
+// fillCavity replaces the tetrahedra in tr.cavity by tetrahedra joining the
+// faces of the cavity boundary to v.
+func (tr *Tetrahedralization) fillCavity(v int) []int {
+	tr.created = tr.created[:0]
+	for _, c := range tr.cavity {
+		for i := 0; i < 4; i++ {
+			u := tr.tets[c].n[i]
+			if tr.mark[u] == tr.stamp {
+				continue
+			}
+			// v sees face i from the same side as the vertex it replaces
+			w := tr.tets[c].v
+			w[i] = v
+			nt := tr.newTetrahedron(w)
+			tr.tets[nt].n[i] = u
+			tr.tets[u].n[tr.tets[u].faceTo(c)] = nt
+			tr.created = append(tr.created, nt)
+		}
+	}
+	// two new tetrahedra are adjacent across the face made of v and an
+	// edge of the cavity boundary
+	for _, nt := range tr.created {
+		w := tr.tets[nt].v
+		for j := 0; j < 4; j++ {
+			if w[j] == v {
+				continue
+			}
+			var e [2]int
+			n := 0
+			for l := 0; l < 4; l++ {
+				if l != j && w[l] != v {
+					e[n] = w[l]
+					n++
+				}
+			}
+			if e[0] > e[1] {
+				e[0], e[1] = e[1], e[0]
+			}
+			if other, ok := tr.edges[e]; ok {
+				tr.tets[nt].n[j] = other[0]
+				tr.tets[other[0]].n[other[1]] = nt
+				delete(tr.edges, e)
+			} else {
+				tr.edges[e] = [2]int{nt, j}
+			}
+		}
+	}
+	for _, c := range tr.cavity {
+		tr.freeTetrahedron(c)
+	}
+	tr.last = tr.created[0]
+	return tr.created
+}
@@ -0,0 +1,163 @@
+package delaunay
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/toy80/predicates"
+)
+
+// checkMesh3 verifies the combinatorial invariants of m and the empty
+// sphere property, and returns six times the total volume.
+func checkMesh3(t *testing.T, m *Mesh3) float64 {
+	t.Helper()
+	volume := 0.0
+	faces := make(map[[3]int]int)
+	edges := make(map[[2]int]bool)
+	vertices := make(map[int]bool)
+	for i, tet := range m.Tetrahedra {
+		p := [4][3]Float{m.Points[tet[0]], m.Points[tet[1]], m.Points[tet[2]], m.Points[tet[3]]}
+		o := predicates.Orient3d(p[0], p[1], p[2], p[3])
+		if o <= 0 {
+			t.Fatalf("tetrahedron %d %v is not positively oriented", i, tet)
+		}
+		volume += float64(o)
+		for f := 0; f < 4; f++ {
+			vertices[tet[f]] = true
+			for g := f + 1; g < 4; g++ {
+				e := [2]int{tet[f], tet[g]}
+				if e[0] > e[1] {
+					e[0], e[1] = e[1], e[0]
+				}
+				edges[e] = true
+			}
+			var face [3]int
+			n := 0
+			for g := 0; g < 4; g++ {
+				if g != f {
+					face[n] = tet[g]
+					n++
+				}
+			}
+			if face[0] > face[1] {
+				face[0], face[1] = face[1], face[0]
+			}
+			if face[1] > face[2] {
+				face[1], face[2] = face[2], face[1]
+			}
+			if face[0] > face[1] {
+				face[0], face[1] = face[1], face[0]
+			}
+			faces[face]++
+
+			u := m.Neighbors[i][f]
+			if u < 0 {
+				continue
+			}
+			back := -1
+			for g := 0; g < 4; g++ {
+				if m.Neighbors[u][g] == i {
+					back = g
+				}
+			}
+			if back < 0 {
+				t.Fatalf("tetrahedron %d is not a neighbor of its neighbor %d", i, u)
+			}
+			for g := 0; g < 4; g++ {
+				if g != back && m.Tetrahedra[u][g] == tet[f] {
+					t.Fatalf("tetrahedra %d %v and %d %v do not share face %d", i, tet, u, m.Tetrahedra[u], f)
+				}
+			}
+			d := m.Points[m.Tetrahedra[u][back]]
+			if predicates.Insphere(p[0], p[1], p[2], p[3], d) > 0 {
+				t.Fatalf("tetrahedron %d %v is not Delaunay, %v is in its circumsphere", i, tet, d)
+			}
+		}
+	}
+	for face, n := range faces {
+		if n > 2 {
+			t.Fatalf("face %v is shared by %d tetrahedra", face, n)
+		}
+	}
+	// Euler characteristic of a ball
+	if len(m.Tetrahedra) > 0 {
+		if chi := len(vertices) - len(edges) + len(faces) - len(m.Tetrahedra); chi != 1 {
+			t.Fatalf("Euler characteristic is %d, want 1", chi)
+		}
+	}
+	return volume
+}
+
+func TestTetrahedralizeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	points := make([][3]Float, 2000)
+	for i := range points {
+		points[i] = [3]Float{Float(r.Float64()), Float(r.Float64()), Float(r.Float64())}
+	}
+	m := NewTetrahedralization(points).Mesh()
+	checkMesh3(t, m)
+	used := make(map[int]bool)
+	for _, tet := range m.Tetrahedra {
+		for _, v := range tet {
+			used[v] = true
+		}
+	}
+	if len(used) != len(points) {
+		t.Errorf("%d vertices used, want %d", len(used), len(points))
+	}
+}
+
+func TestTetrahedralizeLattice(t *testing.T) {
+	const n = 6
+	var points [][3]Float
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			for k := 0; k < n; k++ {
+				points = append(points, [3]Float{Float(i), Float(j), Float(k)})
+			}
+		}
+	}
+	// duplicates and points on the faces of the lattice cells
+	points = append(points, points[:20]...)
+	points = append(points, [3]Float{0.5, 0.5, 0}, [3]Float{2.5, 0, 2.5}, [3]Float{1.5, 1.5, 1.5})
+	m := NewTetrahedralization(points).Mesh()
+	volume := checkMesh3(t, m)
+	if want := 6.0 * (n - 1) * (n - 1) * (n - 1); volume != want {
+		t.Errorf("total volume*6 = %v, want %v", volume, want)
+	}
+}
+
+func TestTetrahedralizeDegenerate(t *testing.T) {
+	tests := []struct {
+		name       string
+		points     [][3]Float
+		tetrahedra int
+	}{
+		{name: "empty", points: nil, tetrahedra: 0},
+		{name: "coplanar", points: [][3]Float{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {1, 1, 0}, {2, 3, 0}}, tetrahedra: 0},
+		{name: "tetrahedron", points: [][3]Float{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}}, tetrahedra: 1},
+		{name: "collinear first", points: [][3]Float{{0, 0, 0}, {1, 0, 0}, {2, 0, 0}, {3, 0, 0}, {0, 1, 0}, {0, 0, 1}}, tetrahedra: 3},
+		{name: "cube", points: [][3]Float{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {1, 1, 0}, {0, 0, 1}, {1, 0, 1}, {0, 1, 1}, {1, 1, 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewTetrahedralization(tt.points).Mesh()
+			checkMesh3(t, m)
+			if tt.tetrahedra > 0 && len(m.Tetrahedra) != tt.tetrahedra {
+				t.Errorf("%d tetrahedra, want %d", len(m.Tetrahedra), tt.tetrahedra)
+			}
+		})
+	}
+}
+
+func TestTetrahedralizeInsert(t *testing.T) {
+	tr := NewTetrahedralization(nil)
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 300; i++ {
+		p := [3]Float{Float(r.Intn(6)), Float(r.Intn(6)), Float(r.Intn(6))}
+		if v := tr.Insert(p); tr.Points()[v] != p {
+			t.Fatalf("Insert() = %d, point %v, want %v", v, tr.Points()[v], p)
+		}
+	}
+	checkMesh3(t, tr.Mesh())
+}
@@ -0,0 +1,158 @@
+package delaunay
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/toy80/predicates"
+)
+
+// checkMesh verifies the combinatorial invariants of m and the empty circle
+// property across the edges that are not constrained.
+func checkMesh(t *testing.T, m *Mesh) {
+	t.Helper()
+	used := make(map[int]bool)
+	for i, tri := range m.Triangles {
+		a, b, c := m.Points[tri[0]], m.Points[tri[1]], m.Points[tri[2]]
+		if predicates.Orient2d(a, b, c) <= 0 {
+			t.Fatalf("triangle %d %v is not counterclockwise", i, tri)
+		}
+		for e := 0; e < 3; e++ {
+			used[tri[e]] = true
+			u := m.Neighbors[i][e]
+			if u < 0 {
+				continue
+			}
+			back := -1
+			for f := 0; f < 3; f++ {
+				if m.Neighbors[u][f] == i {
+					back = f
+				}
+			}
+			if back < 0 {
+				t.Fatalf("triangle %d is not a neighbor of its neighbor %d", i, u)
+			}
+			if m.Triangles[u][(back+1)%3] != tri[(e+2)%3] || m.Triangles[u][(back+2)%3] != tri[(e+1)%3] {
+				t.Fatalf("triangles %d %v and %d %v do not share edge %d", i, tri, u, m.Triangles[u], e)
+			}
+			if m.Constrained[i][e] != m.Constrained[u][back] {
+				t.Fatalf("triangles %d and %d disagree on constraint of their edge", i, u)
+			}
+			if m.Constrained[i][e] {
+				continue
+			}
+			d := m.Points[m.Triangles[u][back]]
+			if predicates.Incircle(a, b, c, d) > 0 {
+				t.Fatalf("triangle %d %v is not Delaunay, %v is in its circumcircle", i, tri, d)
+			}
+		}
+	}
+	// Euler: a triangulation of n vertices with h of them on the hull
+	// boundary has 2n - h - 2 triangles
+	hull := make(map[int]bool)
+	for i, tri := range m.Triangles {
+		for e := 0; e < 3; e++ {
+			if m.Neighbors[i][e] < 0 {
+				hull[tri[(e+1)%3]] = true
+				hull[tri[(e+2)%3]] = true
+			}
+		}
+	}
+	if len(m.Triangles) > 0 && len(m.Triangles) != 2*len(used)-len(hull)-2 {
+		t.Fatalf("%d triangles for %d vertices and %d hull vertices", len(m.Triangles), len(used), len(hull))
+	}
+}
+
+// distinct returns the number of distinct points.
+func distinct(points [][2]Float) int {
+	seen := make(map[[2]Float]bool)
+	for _, p := range points {
+		seen[p] = true
+	}
+	return len(seen)
+}
+
+func countVertices(m *Mesh) int {
+	used := make(map[int]bool)
+	for _, tri := range m.Triangles {
+		for _, v := range tri {
+			used[v] = true
+		}
+	}
+	return len(used)
+}
+
+func TestTriangulateRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	points := make([][2]Float, 2000)
+	for i := range points {
+		points[i] = [2]Float{Float(r.Float64()), Float(r.Float64())}
+	}
+	m := New(points).Mesh()
+	checkMesh(t, m)
+	if got, want := countVertices(m), distinct(points); got != want {
+		t.Errorf("%d vertices used, want %d", got, want)
+	}
+}
+
+func TestTriangulateDegenerate(t *testing.T) {
+	grid := func(n int) [][2]Float {
+		var points [][2]Float
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				points = append(points, [2]Float{Float(i), Float(j)})
+			}
+		}
+		return points
+	}
+	circle := func() [][2]Float {
+		// points on a circle with exact coordinates, from Pythagorean triples
+		var points [][2]Float
+		for _, p := range [][2]Float{{5, 0}, {4, 3}, {3, 4}, {0, 5}} {
+			points = append(points, p, [2]Float{-p[0], p[1]}, [2]Float{p[0], -p[1]}, [2]Float{-p[0], -p[1]})
+		}
+		return points
+	}
+	tests := []struct {
+		name      string
+		points    [][2]Float
+		triangles int
+	}{
+		{name: "empty", points: nil, triangles: 0},
+		{name: "single", points: [][2]Float{{1, 1}}, triangles: 0},
+		{name: "collinear", points: [][2]Float{{0, 0}, {1, 1}, {2, 2}, {3, 3}, {-1, -1}}, triangles: 0},
+		{name: "triangle", points: [][2]Float{{0, 0}, {1, 0}, {0, 1}}, triangles: 1},
+		{name: "collinear then off", points: [][2]Float{{0, 0}, {4, 0}, {1, 0}, {3, 0}, {2, 0}, {2, 1}}, triangles: 4},
+		{name: "duplicates", points: [][2]Float{{0, 0}, {1, 0}, {0, 1}, {1, 0}, {0, 0}, {1, 1}, {1, 1}}, triangles: 2},
+		{name: "grid", points: grid(10), triangles: 2 * 9 * 9},
+		{name: "cocircular", points: circle(), triangles: 10},
+		{name: "collinear hull", points: [][2]Float{{0, 0}, {1, 0}, {2, 0}, {3, 0}, {0, 1}, {3, 1}}, triangles: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(tt.points).Mesh()
+			checkMesh(t, m)
+			if len(m.Triangles) != tt.triangles {
+				t.Errorf("%d triangles, want %d", len(m.Triangles), tt.triangles)
+			}
+		})
+	}
+}
+
+func TestInsert(t *testing.T) {
+	tr := New(nil)
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 500; i++ {
+		// snapped to a coarse grid to get many degenerate configurations
+		p := [2]Float{Float(r.Intn(30)), Float(r.Intn(30))}
+		v := tr.Insert(p)
+		if tr.Points()[v] != p {
+			t.Fatalf("Insert() = %d, point %v, want %v", v, tr.Points()[v], p)
+		}
+	}
+	m := tr.Mesh()
+	checkMesh(t, m)
+	if got, want := countVertices(m), len(m.Points); got != want {
+		t.Errorf("%d vertices used, want %d", got, want)
+	}
+}
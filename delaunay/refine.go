@@ -0,0 +1,400 @@
+package delaunay
+
+import (
+	"errors"
+	"math"
+
+	"github.com/toy80/predicates"
+)
+
+// ErrLimit is returned by Refine when it stops at Quality.MaxPoints.
+var ErrLimit = errors.New("delaunay: refinement point limit reached")
+
+// Quality bounds the triangles produced by Refine.
+type Quality struct {
+	// MinAngle is the smallest angle allowed in a triangle, in degrees.
+	// Ruppert's algorithm is only guaranteed to terminate for bounds up to
+	// about 20.7 degrees, and when no two input segments meet at an angle
+	// smaller than 60 degrees. Zero disables the bound.
+	MinAngle float64
+	// MaxArea is the largest area allowed for a triangle. Zero disables the
+	// bound.
+	MaxArea float64
+	// MaxPoints is the largest number of points Refine may insert. Zero
+	// means no limit.
+	MaxPoints int
+	// Holes holds a point inside each hole of the domain. The triangles
+	// reachable from it without crossing a segment are removed.
+	Holes [][2]Float
+}
+
+// Circumcenter returns the center of the circle through a, b and c,
+// correctly rounded to Float by predicates.Circumcenter2d. For collinear
+// points ok is false.
+func Circumcenter(a, b, c [2]Float) (center [2]Float, ok bool) {
+	r, ok := predicates.Circumcenter2d(a, b, c)
+	return r.Point, ok
+}
+
+// Refine inserts Steiner points until every triangle satisfies q, following
+// Ruppert's algorithm: segments encroached upon, whose diametral circle
+// holds a vertex as decided by Incircle2p, are split at their midpoints;
+// then bad triangles are removed by inserting their circumcenters, unless
+// the circumcenter would encroach upon a segment or is hidden behind one,
+// in which case that segment is split instead.
+//
+// The domain is the region enclosed by the constrained segments, as in a
+// planar straight line graph: the triangles reachable from outside the
+// convex hull or from a point of q.Holes without crossing a segment are
+// outside it, and are neither refined nor returned by Mesh. Without any
+// segment, the domain is the convex hull, whose edges are made segments.
+//
+// Every segment is preserved as a chain of subsegments on it, split at
+// their midpoints, or at another fraction of them if Float cannot
+// represent the midpoint on the segment. Segments that cannot be split
+// exactly, such as the ones too short to split at the precision of Float,
+// are left as they are, as are the triangles that would need them split.
+// Refine returns the number of points inserted; it stops with ErrLimit when
+// q.MaxPoints is reached.
+func (tr *Triangulation) Refine(q Quality) (int, error) {
+	if len(tr.tris) == 0 {
+		return 0, ErrFlat
+	}
+	r := refiner{tr: tr, q: q, skip: make(map[[3]int]bool)}
+	if q.MinAngle > 0 {
+		r.ratio = 1 / (2 * math.Sin(q.MinAngle*math.Pi/180))
+	}
+	r.segs = tr.Segments()
+	if len(r.segs) == 0 {
+		for t := range tr.tris {
+			if k := tr.tris[t].ghostIndex(); k >= 0 {
+				tr.setConstrained(tr.tris[t].v[(k+1)%3], tr.tris[t].v[(k+2)%3])
+			}
+		}
+		r.segs = tr.Segments()
+	}
+	tr.markOutside(q.Holes)
+	err := r.run()
+	return r.inserted, err
+}
+
+// markOutside marks the triangles outside the domain of Refine: the ones
+// reachable from a ghost triangle or from a triangle containing a hole
+// point without crossing a constrained segment.
+func (tr *Triangulation) markOutside(holes [][2]Float) {
+	var stack []int
+	for t := range tr.tris {
+		tr.tris[t].out = false
+		if tr.tris[t].ghostIndex() >= 0 {
+			stack = append(stack, t)
+		}
+	}
+	for _, h := range holes {
+		stack = append(stack, tr.locate(h, tr.last))
+	}
+	for len(stack) > 0 {
+		t := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		tri := &tr.tris[t]
+		if tri.out {
+			continue
+		}
+		tri.out = true
+		for i, u := range tri.n {
+			if !tri.c[i] && !tr.tris[u].out {
+				stack = append(stack, u)
+			}
+		}
+	}
+}
+
+// refiner holds the state of Refine.
+type refiner struct {
+	tr       *Triangulation
+	q        Quality
+	ratio    float64 // largest circumradius to shortest edge ratio
+	inserted int
+	segs     [][2]int        // segments that may be encroached
+	bad      [][3]int        // triangles that may be bad
+	skip     map[[3]int]bool // triangles that cannot be improved
+}
+
+// run splits encroached segments first, then fixes bad triangles one at a
+// time, until neither is left.
+func (r *refiner) run() error {
+	for {
+		if err := r.splitEncroached(); err != nil {
+			return err
+		}
+		if len(r.bad) == 0 {
+			r.findBad()
+			if len(r.bad) == 0 {
+				return nil
+			}
+		}
+		tri := r.bad[len(r.bad)-1]
+		r.bad = r.bad[:len(r.bad)-1]
+		t := r.findTriangle(tri)
+		if t < 0 || !r.isBad(t) {
+			continue
+		}
+		if err := r.fixTriangle(t); err != nil {
+			return err
+		}
+	}
+}
+
+// findBad queues all bad triangles.
+func (r *refiner) findBad() {
+	for t := range r.tr.tris {
+		if r.tr.isFinite(t) && !r.tr.tris[t].out && r.isBad(t) {
+			r.bad = append(r.bad, r.tr.tris[t].v)
+		}
+	}
+}
+
+// findTriangle returns the live triangle with the vertices tri, or -1.
+func (r *refiner) findTriangle(tri [3]int) int {
+	t, i := r.tr.findEdge(tri[0], tri[1])
+	if t < 0 {
+		return -1
+	}
+	for _, s := range [2]int{t, r.tr.tris[t].n[i]} {
+		for _, v := range r.tr.tris[s].v {
+			if v == tri[2] {
+				return s
+			}
+		}
+	}
+	return -1
+}
+
+// isBad reports whether the finite triangle t, inside the domain, violates the quality bounds.
+func (r *refiner) isBad(t int) bool {
+	v := r.tr.tris[t].v
+	if r.skip[v] {
+		return false
+	}
+	p := [3][2]float64{}
+	for i := 0; i < 3; i++ {
+		p[i] = [2]float64{float64(r.tr.points[v[i]][0]), float64(r.tr.points[v[i]][1])}
+	}
+	ab := math.Hypot(p[1][0]-p[0][0], p[1][1]-p[0][1])
+	bc := math.Hypot(p[2][0]-p[1][0], p[2][1]-p[1][1])
+	ca := math.Hypot(p[0][0]-p[2][0], p[0][1]-p[2][1])
+	area := 0.5 * ((p[1][0]-p[0][0])*(p[2][1]-p[0][1]) - (p[1][1]-p[0][1])*(p[2][0]-p[0][0]))
+	if r.q.MaxArea > 0 && area > r.q.MaxArea {
+		return true
+	}
+	if r.ratio > 0 {
+		radius := ab * bc * ca / (4 * area)
+		return radius > r.ratio*math.Min(ab, math.Min(bc, ca))
+	}
+	return false
+}
+
+// insertedVertex counts the new vertex v and queues the segments and
+// triangles it may have spoiled.
+func (r *refiner) insertedVertex(v int) {
+	r.inserted++
+	tr := r.tr
+	tr.around(v, func(t, k int) bool {
+		tri := &tr.tris[t]
+		for i := 0; i < 3; i++ {
+			if tri.c[i] {
+				r.segs = append(r.segs, [2]int{tri.v[(i+1)%3], tri.v[(i+2)%3]})
+			}
+		}
+		if tri.ghostIndex() < 0 && !tri.out {
+			r.bad = append(r.bad, tri.v)
+		}
+		// the vertices across the edges opposite v see new segments too
+		u := tri.n[k]
+		if tr.tris[u].ghostIndex() < 0 && !tr.tris[u].out {
+			r.bad = append(r.bad, tr.tris[u].v)
+		}
+		return true
+	})
+}
+
+func (r *refiner) full() bool {
+	return r.q.MaxPoints > 0 && r.inserted >= r.q.MaxPoints
+}
+
+// splitEncroached splits queued segments until none is encroached.
+func (r *refiner) splitEncroached() error {
+	for len(r.segs) > 0 {
+		s := r.segs[len(r.segs)-1]
+		r.segs = r.segs[:len(r.segs)-1]
+		t, i := r.tr.findEdge(s[0], s[1])
+		if t < 0 || !r.tr.tris[t].c[i] || !r.encroached(t, i) {
+			continue
+		}
+		if _, err := r.splitSegment(s[0], s[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encroached is Triangulation.Encroached for the constrained edge i of t,
+// with the vertices outside the domain left out.
+func (r *refiner) encroached(t, i int) bool {
+	tr := r.tr
+	a, b := tr.tris[t].v[(i+1)%3], tr.tris[t].v[(i+2)%3]
+	pa, pb := tr.points[a], tr.points[b]
+	for _, s := range [2]int{t, tr.tris[t].n[i]} {
+		if tr.tris[s].out {
+			continue
+		}
+		for _, v := range tr.tris[s].v {
+			if v != a && v != b && predicates.Incircle2p(pa, pb, tr.points[v]) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitPoint returns a point strictly inside the segment ab and exactly on
+// it: the midpoint if it rounds to a point on ab, which it does unless it
+// needs one more bit, and otherwise the first of a few other fractions of
+// ab that does. ok is false if there is none.
+func splitPoint(pa, pb [2]Float) (m [2]Float, ok bool) {
+	for _, s := range [...]float64{0.5, 0.375, 0.625, 0.25, 0.75} {
+		m = [2]Float{
+			Float(float64(pa[0]) + s*(float64(pb[0])-float64(pa[0]))),
+			Float(float64(pa[1]) + s*(float64(pb[1])-float64(pa[1]))),
+		}
+		if m != pa && m != pb && predicates.Orient2d(pa, pb, m) == 0 && between(pa, pb, m) {
+			return m, true
+		}
+	}
+	return m, false
+}
+
+// splitSegment splits the constrained segment ab at splitPoint and returns
+// the new vertex, or -1 if the segment cannot be split exactly.
+func (r *refiner) splitSegment(a, b int) (int, error) {
+	if r.full() {
+		return -1, ErrLimit
+	}
+	tr := r.tr
+	m, ok := splitPoint(tr.points[a], tr.points[b])
+	if !ok {
+		return -1, nil
+	}
+	if t, _ := tr.findEdge(a, b); t < 0 {
+		// split already by an earlier split of the same circumcenter
+		return -1, nil
+	}
+	// insertion on a constrained segment splits it
+	v := tr.Insert(m)
+	r.insertedVertex(v)
+	return v, nil
+}
+
+// walk moves from t towards p without crossing constrained segments. It
+// returns the triangle containing p, or the triangle and edge where a
+// segment separates it from p, or edge -2 if it gets lost or leaves the
+// hull across an edge that is not a segment.
+func (r *refiner) walk(t int, p [2]Float) (int, int) {
+	tr := r.tr
+	limit := 4*len(tr.tris) + 64
+	for step := 0; step < limit; step++ {
+		tri := &tr.tris[t]
+		blocked := -1
+		next := -1
+		o := int(tr.random() % 3)
+		for j := 0; j < 3 && next < 0; j++ {
+			i := (o + j) % 3
+			a, b := tri.v[(i+1)%3], tri.v[(i+2)%3]
+			if predicates.Orient2d(tr.points[a], tr.points[b], p) < 0 {
+				if tri.c[i] {
+					blocked = i
+				} else {
+					next = tri.n[i]
+				}
+			}
+		}
+		if next < 0 {
+			return t, blocked
+		}
+		if tr.tris[next].ghostIndex() >= 0 {
+			return t, -2
+		}
+		t = next
+	}
+	return t, -2
+}
+
+// fixTriangle inserts the circumcenter of the bad triangle t, or splits the
+// segments it would encroach upon.
+func (r *refiner) fixTriangle(t int) error {
+	tr := r.tr
+	v := tr.tris[t].v
+	c, ok := Circumcenter(tr.points[v[0]], tr.points[v[1]], tr.points[v[2]])
+	if !ok {
+		r.skip[v] = true
+		return nil
+	}
+	s, i := r.walk(t, c)
+	if i == -2 {
+		r.skip[v] = true
+		return nil
+	}
+	if i >= 0 {
+		// the circumcenter is hidden behind a segment
+		a, b := tr.tris[s].v[(i+1)%3], tr.tris[s].v[(i+2)%3]
+		w, err := r.splitSegment(a, b)
+		if w < 0 && err == nil {
+			r.skip[v] = true
+		}
+		return err
+	}
+	for _, u := range tr.tris[s].v {
+		if tr.points[u] == c {
+			r.skip[v] = true
+			return nil
+		}
+	}
+
+	// the segments on the boundary of the cavity of c are the ones it
+	// could encroach upon
+	tr.digCavity(s, c, tr.isConstrained)
+	var encroached [][2]int
+	for _, d := range tr.cavity {
+		for j := 0; j < 3; j++ {
+			if !tr.tris[d].c[j] {
+				continue
+			}
+			a, b := tr.tris[d].v[(j+1)%3], tr.tris[d].v[(j+2)%3]
+			if predicates.Incircle2p(tr.points[a], tr.points[b], c) > 0 {
+				encroached = append(encroached, [2]int{a, b})
+			}
+		}
+	}
+	if len(encroached) > 0 {
+		split := false
+		for _, e := range encroached {
+			w, err := r.splitSegment(e[0], e[1])
+			if err != nil {
+				return err
+			}
+			split = split || w >= 0
+		}
+		if !split {
+			r.skip[v] = true
+		}
+		return nil
+	}
+	if r.full() {
+		return ErrLimit
+	}
+	tr.points = append(tr.points, c)
+	tr.vtri = append(tr.vtri, -1)
+	w := len(tr.points) - 1
+	tr.fillCavity(w)
+	r.insertedVertex(w)
+	return nil
+}
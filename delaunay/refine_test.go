@@ -0,0 +1,229 @@
+package delaunay
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/toy80/predicates"
+)
+
+// minAngle returns the smallest angle of the triangle abc in degrees.
+func minAngle(a, b, c [2]Float) float64 {
+	angle := func(p, q, r [2]Float) float64 {
+		ux, uy := float64(q[0])-float64(p[0]), float64(q[1])-float64(p[1])
+		vx, vy := float64(r[0])-float64(p[0]), float64(r[1])-float64(p[1])
+		return math.Abs(math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)) * 180 / math.Pi
+	}
+	return math.Min(angle(a, b, c), math.Min(angle(b, c, a), angle(c, a, b)))
+}
+
+// covered reports whether the segments of tr form a chain from a to b along
+// the straight line between them.
+func covered(tr *Triangulation, a, b int) bool {
+	pa, pb := tr.Points()[a], tr.Points()[b]
+	near := func(p [2]Float) bool {
+		dx, dy := float64(pb[0])-float64(pa[0]), float64(pb[1])-float64(pa[1])
+		px, py := float64(p[0])-float64(pa[0]), float64(p[1])-float64(pa[1])
+		l := math.Hypot(dx, dy)
+		s := (px*dx + py*dy) / (l * l)
+		return s >= 0 && s <= 1 && math.Abs(px*dy-py*dx)/l < 1e-4*l
+	}
+	adj := make(map[int][]int)
+	for _, s := range tr.Segments() {
+		if near(tr.Points()[s[0]]) && near(tr.Points()[s[1]]) {
+			adj[s[0]] = append(adj[s[0]], s[1])
+			adj[s[1]] = append(adj[s[1]], s[0])
+		}
+	}
+	seen := map[int]bool{a: true}
+	queue := []int{a}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, u := range adj[v] {
+			if !seen[u] {
+				seen[u] = true
+				queue = append(queue, u)
+			}
+		}
+	}
+	return seen[b]
+}
+
+// checkSubsegments fails if a segment of tr is not exactly on one of the
+// input segments.
+func checkSubsegments(t *testing.T, tr *Triangulation, segments [][2]int) {
+	t.Helper()
+	pts := tr.Points()
+	on := func(s [2]int, p [2]Float) bool {
+		pa, pb := pts[s[0]], pts[s[1]]
+		return p == pa || p == pb || predicates.Orient2d(pa, pb, p) == 0 && between(pa, pb, p)
+	}
+next:
+	for _, sub := range tr.Segments() {
+		for _, s := range segments {
+			if on(s, pts[sub[0]]) && on(s, pts[sub[1]]) {
+				continue next
+			}
+		}
+		t.Errorf("segment %v %v-%v is off the input segments", sub, pts[sub[0]], pts[sub[1]])
+	}
+}
+
+func TestRefine(t *testing.T) {
+	points := [][2]Float{
+		{0, 0}, {8, 0}, {8, 8}, {0, 8},
+		{1, 1}, {7, 5},
+		{2, 6}, {3, 6.5}, {2.5, 7},
+		{6, 1.5}, {6.1, 1.5},
+	}
+	segments := [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}, {4, 5}, {6, 7}, {7, 8}, {8, 6}, {9, 10}}
+	tests := []struct {
+		name string
+		q    Quality
+	}{
+		{name: "angle", q: Quality{MinAngle: 20}},
+		{name: "area", q: Quality{MaxArea: 0.5}},
+		{name: "both", q: Quality{MinAngle: 25, MaxArea: 0.1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, err := NewConstrained(points, segments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			n, err := tr.Refine(tt.q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n == 0 || len(tr.Points()) != len(points)+n {
+				t.Errorf("Refine() = %d with %d points", n, len(tr.Points()))
+			}
+			m := tr.Mesh()
+			checkMesh(t, m)
+			for _, tri := range m.Triangles {
+				a, b, c := m.Points[tri[0]], m.Points[tri[1]], m.Points[tri[2]]
+				if tt.q.MinAngle > 0 && minAngle(a, b, c) < tt.q.MinAngle-1e-3 {
+					t.Errorf("triangle %v has angle %v", tri, minAngle(a, b, c))
+				}
+				area := 0.5 * math.Abs(float64((b[0]-a[0])*(c[1]-a[1])-(b[1]-a[1])*(c[0]-a[0])))
+				if tt.q.MaxArea > 0 && area > tt.q.MaxArea*(1+1e-6) {
+					t.Errorf("triangle %v has area %v", tri, area)
+				}
+			}
+			for _, s := range tr.Segments() {
+				if tr.Encroached(s[0], s[1]) {
+					t.Errorf("segment %v is encroached", s)
+				}
+			}
+			for _, s := range segments {
+				if !covered(tr, s[0], s[1]) {
+					t.Errorf("segment %v is not preserved", s)
+				}
+			}
+			checkSubsegments(t, tr, segments)
+		})
+	}
+}
+
+func TestRefineDomain(t *testing.T) {
+	outer := [][2]Float{{0, 0}, {7, 1}, {8, 7}, {1, 6}}
+	hole := [][2]Float{{3, 3}, {4, 5}, {5, 3.5}}
+	points := append(append([][2]Float{}, outer...), hole...)
+	// points outside the domain
+	points = append(points, [2]Float{-1, -1}, [2]Float{9, 0}, [2]Float{10, 10}, [2]Float{4, 4})
+	segments := [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}, {4, 5}, {5, 6}, {6, 4}}
+	tr, err := NewConstrained(points, segments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := Quality{MinAngle: 20, MaxArea: 0.2, Holes: [][2]Float{{4, 3.75}}}
+	if _, err := tr.Refine(q); err != nil {
+		t.Fatal(err)
+	}
+	checkSubsegments(t, tr, segments)
+
+	area2 := func(poly [][2]Float) float64 {
+		sum := 0.0
+		for i, a := range poly {
+			b := poly[(i+1)%len(poly)]
+			sum += float64(a[0])*float64(b[1]) - float64(a[1])*float64(b[0])
+		}
+		return math.Abs(sum)
+	}
+	want := (area2(outer) - area2(hole)) / 2
+	got := 0.0
+	m := tr.Mesh()
+	for i, tri := range m.Triangles {
+		a, b, c := m.Points[tri[0]], m.Points[tri[1]], m.Points[tri[2]]
+		got += area2([][2]Float{a, b, c}) / 2
+		centroid := [2]Float{(a[0] + b[0] + c[0]) / 3, (a[1] + b[1] + c[1]) / 3}
+		if loc := predicates.PointInPolygon([][][2]Float{outer, hole}, centroid, predicates.EvenOdd); loc != predicates.Inside {
+			t.Errorf("triangle %v is outside the domain", tri)
+		}
+		if minAngle(a, b, c) < q.MinAngle-1e-3 {
+			t.Errorf("triangle %v has angle %v", tri, minAngle(a, b, c))
+		}
+		for e := 0; e < 3; e++ {
+			if m.Neighbors[i][e] < 0 && !m.Constrained[i][e] {
+				t.Errorf("triangle %v has a boundary edge %d that is not a segment", tri, e)
+			}
+		}
+	}
+	if math.Abs(got-want) > 1e-4 {
+		t.Errorf("mesh area = %v, want %v", got, want)
+	}
+}
+
+func TestSplitPoint(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		pa := [2]Float{Float(rnd.Float64()*200 - 100), Float(rnd.Float64()*200 - 100)}
+		pb := [2]Float{Float(rnd.Float64()*200 - 100), Float(rnd.Float64()*200 - 100)}
+		if pa == pb {
+			continue
+		}
+		m, ok := splitPoint(pa, pb)
+		if ok && (predicates.Orient2d(pa, pb, m) != 0 || !between(pa, pb, m)) {
+			t.Errorf("splitPoint(%v, %v) = %v, off the open segment", pa, pb, m)
+		}
+	}
+	// one unit in the last place apart: nothing in between
+	pa := [2]Float{1, 1}
+	pb := [2]Float{math.Nextafter32(1, 2), 1}
+	if m, ok := splitPoint(pa, pb); ok {
+		t.Errorf("splitPoint(%v, %v) = %v, want none", pa, pb, m)
+	}
+}
+
+func TestRefineLimit(t *testing.T) {
+	tr := New([][2]Float{{0, 0}, {1, 0}, {0, 1}, {1, 1}})
+	n, err := tr.Refine(Quality{MaxArea: 1e-4, MaxPoints: 50})
+	if err != ErrLimit || n != 50 {
+		t.Errorf("Refine() = %d, %v, want 50, %v", n, err, ErrLimit)
+	}
+	checkMesh(t, tr.Mesh())
+	if _, err := New(nil).Refine(Quality{MinAngle: 20}); err != ErrFlat {
+		t.Errorf("Refine() of empty triangulation = %v, want %v", err, ErrFlat)
+	}
+}
+
+func TestCircumcenter(t *testing.T) {
+	tests := []struct {
+		a, b, c [2]Float
+		want    [2]Float
+		ok      bool
+	}{
+		{a: [2]Float{0, 0}, b: [2]Float{2, 0}, c: [2]Float{0, 2}, want: [2]Float{1, 1}, ok: true},
+		{a: [2]Float{5, 0}, b: [2]Float{-3, 4}, c: [2]Float{0, -5}, want: [2]Float{0, 0}, ok: true},
+		{a: [2]Float{1000, 1000}, b: [2]Float{1004, 1000}, c: [2]Float{1000, 1002}, want: [2]Float{1002, 1001}, ok: true},
+		{a: [2]Float{0, 0}, b: [2]Float{1, 1}, c: [2]Float{3, 3}, ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := Circumcenter(tt.a, tt.b, tt.c)
+		if ok != tt.ok || ok && got != tt.want {
+			t.Errorf("Circumcenter(%v, %v, %v) = %v, %v, want %v, %v", tt.a, tt.b, tt.c, got, ok, tt.want, tt.ok)
+		}
+	}
+}
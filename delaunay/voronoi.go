@@ -0,0 +1,245 @@
+package delaunay
+
+import (
+	"math"
+	"sort"
+
+	"github.com/toy80/predicates"
+)
+
+// Voronoi returns the Voronoi cell of every vertex of the triangulation,
+// clipped to the box from min to max, as a counterclockwise polygon indexed
+// like Points. Vertices that are not in the triangulation, and vertices
+// whose cell misses the box, get a nil cell. The cells are the duals of the
+// triangles, so they form the Voronoi diagram only if the triangulation has
+// no constrained segments.
+//
+// The vertices of the cells are circumcenters, correctly rounded by
+// Circumcenter, and clipping works in float64. Triangles whose
+// circumcircles Incircle finds to be the same, which happens when four or
+// more sites are cocircular, share a single center, and both cells on
+// either side of an edge clip it to the box the same way, so neighbouring
+// cells always have identical vertices along their common edge.
+func (tr *Triangulation) Voronoi(min, max [2]Float) [][][2]Float {
+	cells := make([][][2]Float, len(tr.points))
+	lo := [2]float64{float64(min[0]), float64(min[1])}
+	hi := [2]float64{float64(max[0]), float64(max[1])}
+	if len(tr.tris) == 0 {
+		tr.collinearCells(cells, lo, hi)
+		return cells
+	}
+
+	centers := tr.circumcenters()
+	// far points along the rays out of the hull must be well outside the
+	// box, the sites and the finite centers
+	r := math.Max(norm(lo), norm(hi))
+	for v, p := range tr.points {
+		if tr.vtri[v] >= 0 {
+			r = math.Max(r, norm(point64(p)))
+		}
+	}
+	for t, c := range centers {
+		if tr.isFinite(t) {
+			r = math.Max(r, norm(c))
+		}
+	}
+	far := 8*r + 1
+
+	for v := range tr.points {
+		if !tr.validVertex(v) {
+			continue
+		}
+		var poly [][2]float64
+		tr.around(v, func(t, k int) bool {
+			tri := &tr.tris[t]
+			g := tri.ghostIndex()
+			if g < 0 {
+				poly = append(poly, centers[t])
+				return true
+			}
+			c := centers[tri.n[g]]
+			n := tr.outwardNormal(t)
+			poly = append(poly, [2]float64{c[0] + n[0]*far, c[1] + n[1]*far})
+			if tri.v[(k+2)%3] == ghost {
+				// the next triangle is the other ghost at v, join the two
+				// rays through a point in the direction between them
+				m := tr.outwardNormal(tri.n[(k+1)%3])
+				d := unit([2]float64{n[0] + m[0], n[1] + m[1]})
+				p := point64(tr.points[v])
+				poly = append(poly, [2]float64{p[0] + d[0]*far, p[1] + d[1]*far})
+			}
+			return true
+		})
+		cells[v] = roundCell(clipBox(poly, lo, hi))
+	}
+	return cells
+}
+
+// circumcenters returns the circumcenter of every finite triangle, the same
+// for triangles with the same circumcircle.
+func (tr *Triangulation) circumcenters() [][2]float64 {
+	parent := make([]int, len(tr.tris))
+	for t := range parent {
+		parent[t] = t
+	}
+	var find func(t int) int
+	find = func(t int) int {
+		if parent[t] != t {
+			parent[t] = find(parent[t])
+		}
+		return parent[t]
+	}
+	for t := range tr.tris {
+		if !tr.isFinite(t) {
+			continue
+		}
+		v := tr.tris[t].v
+		for _, u := range tr.tris[t].n {
+			if u < t || !tr.isFinite(u) {
+				continue
+			}
+			d := tr.tris[u].v[tr.tris[u].edgeTo(t)]
+			if predicates.Incircle(tr.points[v[0]], tr.points[v[1]], tr.points[v[2]], tr.points[d]) == 0 {
+				parent[find(u)] = find(t)
+			}
+		}
+	}
+	centers := make([][2]float64, len(tr.tris))
+	for t := range tr.tris {
+		if tr.isFinite(t) && find(t) == t {
+			v := tr.tris[t].v
+			c, _ := Circumcenter(tr.points[v[0]], tr.points[v[1]], tr.points[v[2]])
+			centers[t] = point64(c)
+		}
+	}
+	for t := range tr.tris {
+		if tr.isFinite(t) {
+			centers[t] = centers[find(t)]
+		}
+	}
+	return centers
+}
+
+// outwardNormal returns the unit normal of the hull edge of the ghost
+// triangle t, pointing out of the hull.
+func (tr *Triangulation) outwardNormal(t int) [2]float64 {
+	tri := &tr.tris[t]
+	k := tri.ghostIndex()
+	a, b := point64(tr.points[tri.v[(k+1)%3]]), point64(tr.points[tri.v[(k+2)%3]])
+	// the outside is on the left of the edge from a to b
+	return unit([2]float64{a[1] - b[1], b[0] - a[0]})
+}
+
+// collinearCells fills cells for a triangulation without triangles, whose
+// vertices are all on a line: the cells are slabs between the bisectors of
+// consecutive vertices.
+func (tr *Triangulation) collinearCells(cells [][][2]Float, lo, hi [2]float64) {
+	sites := append([]int(nil), tr.pending...)
+	sort.Slice(sites, func(i, j int) bool {
+		return lessXY(tr.points[sites[i]], tr.points[sites[j]])
+	})
+	if len(sites) == 1 {
+		box := [][2]float64{lo, {hi[0], lo[1]}, hi, {lo[0], hi[1]}}
+		cells[sites[0]] = roundCell(clipBox(box, lo, hi))
+		return
+	}
+	if len(sites) == 0 {
+		return
+	}
+	r := math.Max(norm(lo), norm(hi))
+	for _, v := range sites {
+		r = math.Max(r, norm(point64(tr.points[v])))
+	}
+	far := 8*r + 1
+	first, last := point64(tr.points[sites[0]]), point64(tr.points[sites[len(sites)-1]])
+	d := unit([2]float64{last[0] - first[0], last[1] - first[1]})
+	n := [2]float64{-d[1], d[0]}
+	// the bisector of consecutive sites goes through their midpoint, which
+	// is exact in float64
+	mid := func(i int) [2]float64 {
+		switch i {
+		case 0:
+			return [2]float64{first[0] - d[0]*far, first[1] - d[1]*far}
+		case len(sites):
+			return [2]float64{last[0] + d[0]*far, last[1] + d[1]*far}
+		}
+		a, b := point64(tr.points[sites[i-1]]), point64(tr.points[sites[i]])
+		return [2]float64{(a[0] + b[0]) / 2, (a[1] + b[1]) / 2}
+	}
+	for i, v := range sites {
+		p, q := mid(i), mid(i+1)
+		poly := [][2]float64{
+			{p[0] - n[0]*far, p[1] - n[1]*far},
+			{q[0] - n[0]*far, q[1] - n[1]*far},
+			{q[0] + n[0]*far, q[1] + n[1]*far},
+			{p[0] + n[0]*far, p[1] + n[1]*far},
+		}
+		cells[v] = roundCell(clipBox(poly, lo, hi))
+	}
+}
+
+// clipBox clips the convex polygon poly to the box from lo to hi. The point
+// where an edge crosses a side of the box is computed from its endpoints in
+// lexicographic order, so it is the same whichever way the edge runs.
+func clipBox(poly [][2]float64, lo, hi [2]float64) [][2]float64 {
+	for side := 0; side < 4; side++ {
+		axis := side % 2
+		bound, sign := lo[axis], 1.0
+		if side >= 2 {
+			bound, sign = hi[axis], -1
+		}
+		inside := func(p [2]float64) bool { return sign*(p[axis]-bound) >= 0 }
+		var out [][2]float64
+		for i, q := range poly {
+			p := poly[(i+len(poly)-1)%len(poly)]
+			if inside(q) != inside(p) {
+				a, b := p, q
+				if b[0] < a[0] || b[0] == a[0] && b[1] < a[1] {
+					a, b = b, a
+				}
+				s := (bound - a[axis]) / (b[axis] - a[axis])
+				var x [2]float64
+				x[axis] = bound
+				x[1-axis] = a[1-axis] + s*(b[1-axis]-a[1-axis])
+				out = append(out, x)
+			}
+			if inside(q) {
+				out = append(out, q)
+			}
+		}
+		poly = out
+	}
+	return poly
+}
+
+// roundCell rounds poly to Float and drops repeated vertices. It returns nil
+// if fewer than three vertices are left.
+func roundCell(poly [][2]float64) [][2]Float {
+	var cell [][2]Float
+	for _, p := range poly {
+		q := [2]Float{Float(p[0]), Float(p[1])}
+		if len(cell) == 0 || cell[len(cell)-1] != q {
+			cell = append(cell, q)
+		}
+	}
+	for len(cell) > 1 && cell[0] == cell[len(cell)-1] {
+		cell = cell[:len(cell)-1]
+	}
+	if len(cell) < 3 {
+		return nil
+	}
+	return cell
+}
+
+func point64(p [2]Float) [2]float64 {
+	return [2]float64{float64(p[0]), float64(p[1])}
+}
+
+func norm(p [2]float64) float64 {
+	return math.Hypot(p[0], p[1])
+}
+
+func unit(p [2]float64) [2]float64 {
+	l := norm(p)
+	return [2]float64{p[0] / l, p[1] / l}
+}
@@ -0,0 +1,159 @@
+package delaunay
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/toy80/predicates"
+)
+
+// checkCells verifies that the cells tile the box: they are convex and
+// counterclockwise, their areas add up to the area of the box, and every
+// vertex inside the box is shared by at least three cells.
+func checkCells(t *testing.T, cells [][][2]Float, min, max [2]Float) {
+	t.Helper()
+	var total []Float
+	shared := make(map[[2]Float]int)
+	for v, cell := range cells {
+		if cell == nil {
+			continue
+		}
+		if !predicates.IsConvex(cell) || predicates.PolygonOrientation(cell) <= 0 {
+			t.Fatalf("cell %d %v is not convex and counterclockwise", v, cell)
+		}
+		// FastExpansionSumZeroElim reads one component past each input,
+		// so the expansions get a spare one
+		area := append(predicates.PolygonArea2(cell), 0)
+		if len(total) == 0 {
+			total = area[:len(area)-1]
+		} else {
+			sum := make([]Float, len(total)+len(area))
+			n := predicates.FastExpansionSumZeroElim(len(total), &total[0], len(area)-1, &area[0], &sum[0])
+			total = sum[:n]
+		}
+		for _, p := range cell {
+			shared[p]++
+		}
+	}
+	if len(shared) == 0 {
+		return
+	}
+	box := 2 * (max[0] - min[0]) * (max[1] - min[1])
+	if got := predicates.Estimate(len(total), first(total)); got != box {
+		t.Errorf("cells cover area %v, want %v", got/2, box/2)
+	}
+	for p, n := range shared {
+		if p[0] > min[0] && p[0] < max[0] && p[1] > min[1] && p[1] < max[1] && n < 3 {
+			t.Errorf("vertex %v is shared by %d cells", p, n)
+		}
+	}
+}
+
+// first returns a pointer to the first element of e, or to a zero if it is
+// empty.
+func first(e []Float) *Float {
+	if len(e) == 0 {
+		return new(Float)
+	}
+	return &e[0]
+}
+
+func TestVoronoiGrid(t *testing.T) {
+	var points [][2]Float
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			points = append(points, [2]Float{Float(i), Float(j)})
+		}
+	}
+	min, max := [2]Float{-1, -1}, [2]Float{4, 4}
+	cells := New(points).Voronoi(min, max)
+	checkCells(t, cells, min, max)
+	// the cell of the point at (1, 2) is the unit square around it, its
+	// corners are the shared centers of cocircular squares
+	want := map[[2]Float]bool{{0.5, 1.5}: true, {1.5, 1.5}: true, {1.5, 2.5}: true, {0.5, 2.5}: true}
+	if cell := cells[6]; len(cell) != 4 {
+		t.Errorf("cell of %v = %v, want a square", points[6], cell)
+	} else {
+		for _, p := range cell {
+			if !want[p] {
+				t.Errorf("cell of %v = %v, want a unit square", points[6], cell)
+			}
+		}
+	}
+}
+
+func TestVoronoiCocircular(t *testing.T) {
+	// points on a circle with exact coordinates, and its center
+	points := [][2]Float{{0, 0}}
+	for _, p := range [][2]Float{{5, 0}, {4, 3}, {3, 4}, {0, 5}} {
+		points = append(points, p, [2]Float{-p[0], p[1]}, [2]Float{p[0], -p[1]}, [2]Float{-p[0], -p[1]})
+	}
+	min, max := [2]Float{-10, -10}, [2]Float{10, 10}
+	cells := New(points).Voronoi(min, max)
+	checkCells(t, cells, min, max)
+	if n := len(cells[0]); n != 12 {
+		t.Errorf("center cell has %d vertices, want 12", n)
+	}
+}
+
+func TestVoronoiRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	points := make([][2]Float, 500)
+	for i := range points {
+		points[i] = [2]Float{Float(r.Intn(200)) / 8, Float(r.Intn(200)) / 8}
+	}
+	min, max := [2]Float{2, 3}, [2]Float{20, 22}
+	cells := New(points).Voronoi(min, max)
+	checkCells(t, cells, min, max)
+	// points strictly inside a cell are at least as close to its site as
+	// to any other site
+	for i := 0; i < 200; i++ {
+		q := [2]Float{2 + Float(r.Float64())*18, 3 + Float(r.Float64())*19}
+		best := -1
+		for v, p := range points {
+			if best < 0 || dist2(p, q) < dist2(points[best], q) {
+				best = v
+			}
+		}
+		for v, cell := range cells {
+			if cell != nil && predicates.PointInPolygon([][][2]Float{cell}, q, predicates.NonZero) == predicates.Inside &&
+				dist2(points[v], q) > dist2(points[best], q)*(1+1e-5) {
+				t.Fatalf("%v is in the cell of %v but closer to %v", q, points[v], points[best])
+			}
+		}
+	}
+}
+
+func dist2(a, b [2]Float) float64 {
+	dx, dy := float64(a[0])-float64(b[0]), float64(a[1])-float64(b[1])
+	return dx*dx + dy*dy
+}
+
+func TestVoronoiDegenerate(t *testing.T) {
+	min, max := [2]Float{0, 0}, [2]Float{8, 8}
+	tests := []struct {
+		name   string
+		points [][2]Float
+		cells  int
+	}{
+		{name: "empty", points: nil, cells: 0},
+		{name: "single", points: [][2]Float{{3, 3}}, cells: 1},
+		{name: "collinear", points: [][2]Float{{1, 1}, {5, 5}, {3, 3}, {3, 3}}, cells: 3},
+		{name: "outside", points: [][2]Float{{1, 1}, {2, 1}, {1, 2}, {-50, -50}}, cells: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cells := New(tt.points).Voronoi(min, max)
+			checkCells(t, cells, min, max)
+			n := 0
+			for _, c := range cells {
+				if c != nil {
+					n++
+				}
+			}
+			if n != tt.cells {
+				t.Errorf("%d cells, want %d", n, tt.cells)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package predicates
+
+// CompareDistance2d compares the distance from a to b with the distance from
+// c to d, returning -1, 0 or 1 as |ab| is shorter than, equal to or longer
+// than |cd|. With c = a it tells which of b and d is closer to a.
+//
+// The squared lengths are first compared in floating point, and only when
+// their difference is within the error bound are they evaluated exactly as
+// expansions.
+func CompareDistance2d(a, b, c, d [2]Float) int {
+	return compareDistance(a[:], b[:], c[:], d[:])
+}
+
+// CompareDistance3d is CompareDistance2d in three dimensions.
+func CompareDistance3d(a, b, c, d [3]Float) int {
+	return compareDistance(a[:], b[:], c[:], d[:])
+}
+
+func compareDistance(a, b, c, d []Float) int {
+	var s1, s2 Float
+	for k := range a {
+		u, v := a[k]-b[k], c[k]-d[k]
+		s1 += u * u
+		s2 += v * v
+	}
+	det := s1 - s2
+	errbound := dsterrboundA * (s1 + s2)
+	if det > errbound {
+		return 1
+	}
+	if -det > errbound {
+		return -1
+	}
+	return expansionSign(sumExpansions(distance2(a, b), negate(distance2(c, d)), nil))
+}
+
+// distance2 returns the squared distance between a and b as an expansion.
+func distance2(a, b []Float) []Float {
+	var sum []Float
+	for k := range a {
+		hi, lo := twoDiff(a[k], b[k])
+		sum = sumExpansions(sum, square(hi, lo), nil)
+	}
+	return sum
+}
+
+// CompareDistanceToLine compares the distances from p and from q to the
+// line through a and b, returning -1, 0 or 1 as p is closer to it than q,
+// as close, or farther. The distances are |Orient2d(a, b, p)| and
+// |Orient2d(a, b, q)| divided by the same |ab|, so the Orient2d
+// determinants are compared, in floating point when their error bounds
+// allow it and exactly otherwise. If a and b coincide the line degenerates
+// to that point, and the distances to it are compared.
+func CompareDistanceToLine(a, b, p, q [2]Float) int {
+	if a == b {
+		return CompareDistance2d(p, a, q, a)
+	}
+	dp, sp := orient2dSum(a, b, p)
+	dq, sq := orient2dSum(a, b, q)
+	det := abs(dp) - abs(dq)
+	errbound := dsterrboundA * (sp + sq)
+	if det > errbound {
+		return 1
+	}
+	if -det > errbound {
+		return -1
+	}
+	var w, x [12]Float
+	e := w[:orient2dExact(a, b, p, &w)]
+	f := x[:orient2dExact(a, b, q, &x)]
+	if expansionSign(e) < 0 {
+		negate(e)
+	}
+	if expansionSign(f) > 0 {
+		negate(f)
+	}
+	return expansionSign(sumExpansions(e, f, nil))
+}
+
+// orient2dSum returns the Orient2d determinant of a, b and c in floating
+// point, and the sum of the magnitudes of its two products that bounds its
+// error.
+func orient2dSum(a, b, c [2]Float) (det, detsum Float) {
+	detleft := (a[0] - c[0]) * (b[1] - c[1])
+	detright := (a[1] - c[1]) * (b[0] - c[0])
+	return detleft - detright, abs(detleft) + abs(detright)
+}
@@ -0,0 +1,104 @@
+package predicates
+
+import (
+	"math/big"
+	"testing"
+)
+
+func ratDistance2(a, b []Float) *big.Rat {
+	s := new(big.Rat)
+	for k := range a {
+		d := new(big.Rat).Sub(new(big.Rat).SetFloat64(float64(a[k])), new(big.Rat).SetFloat64(float64(b[k])))
+		s.Add(s, d.Mul(d, d))
+	}
+	return s
+}
+
+// ratOrient2d is the Orient2d determinant computed with rationals.
+func ratOrient2d(a, b, c [2]Float) *big.Rat {
+	r := func(x Float) *big.Rat { return new(big.Rat).SetFloat64(float64(x)) }
+	acx, acy := r(a[0]).Sub(r(a[0]), r(c[0])), r(a[1]).Sub(r(a[1]), r(c[1]))
+	bcx, bcy := r(b[0]).Sub(r(b[0]), r(c[0])), r(b[1]).Sub(r(b[1]), r(c[1]))
+	return acx.Mul(acx, bcy).Sub(acx, bcx.Mul(bcx, acy))
+}
+
+func TestCompareDistance2d(t *testing.T) {
+	tests := []struct {
+		a, b, c, d [2]Float
+		want       int
+	}{
+		{[2]Float{0, 0}, [2]Float{3, 4}, [2]Float{0, 0}, [2]Float{5, 0}, 0},
+		{[2]Float{0, 0}, [2]Float{3, 4}, [2]Float{1, 1}, [2]Float{1, 6.0001}, -1},
+		{[2]Float{1, 1}, [2]Float{1, 1}, [2]Float{2, 2}, [2]Float{2, 2}, 0},
+		{[2]Float{1, 1}, [2]Float{1, 1.0000001}, [2]Float{2, 2}, [2]Float{2, 2}, 1},
+		// within the error bound of the floating point comparison
+		{[2]Float{0, 0}, [2]Float{4097, 0}, [2]Float{0, 0}, [2]Float{4096.9995, 0}, 1},
+	}
+	for _, tt := range tests {
+		if got := CompareDistance2d(tt.a, tt.b, tt.c, tt.d); got != tt.want {
+			t.Errorf("CompareDistance2d(%v, %v, %v, %v) = %d, want %d", tt.a, tt.b, tt.c, tt.d, got, tt.want)
+		}
+		if got := CompareDistance2d(tt.c, tt.d, tt.a, tt.b); got != -tt.want {
+			t.Errorf("CompareDistance2d(%v, %v, %v, %v) = %d, want %d", tt.c, tt.d, tt.a, tt.b, got, -tt.want)
+		}
+	}
+}
+
+func TestCompareDistanceRand(t *testing.T) {
+	for i := 0; i < 20000; i++ {
+		var a, b, c, d [3]Float
+		for k := 0; k < 3; k++ {
+			a[k], b[k], c[k], d[k] = narrowRealRand(), narrowRealRand(), narrowRealRand(), narrowRealRand()
+		}
+		if i%2 == 0 {
+			// b and d on the same sphere about a, up to the rounding of d
+			c = a
+			d = [3]Float{a[0] + (b[1] - a[1]), a[1] - (b[0] - a[0]), b[2]}
+		}
+		want := ratDistance2(a[:], b[:]).Cmp(ratDistance2(c[:], d[:]))
+		if got := CompareDistance3d(a, b, c, d); got != want {
+			t.Errorf("CompareDistance3d(%v, %v, %v, %v) = %d, want %d", a, b, c, d, got, want)
+		}
+		a2, b2, c2, d2 := [2]Float{a[0], a[1]}, [2]Float{b[0], b[1]}, [2]Float{c[0], c[1]}, [2]Float{d[0], d[1]}
+		want = ratDistance2(a2[:], b2[:]).Cmp(ratDistance2(c2[:], d2[:]))
+		if got := CompareDistance2d(a2, b2, c2, d2); got != want {
+			t.Errorf("CompareDistance2d(%v, %v, %v, %v) = %d, want %d", a2, b2, c2, d2, got, want)
+		}
+	}
+}
+
+func TestCompareDistanceToLine(t *testing.T) {
+	tests := []struct {
+		a, b, p, q [2]Float
+		want       int
+	}{
+		{[2]Float{0, 0}, [2]Float{1, 0}, [2]Float{5, 1}, [2]Float{-7, -1}, 0},
+		{[2]Float{0, 0}, [2]Float{1, 0}, [2]Float{5, 1}, [2]Float{-7, -2}, -1},
+		{[2]Float{0, 0}, [2]Float{1, 1}, [2]Float{3, 3}, [2]Float{0, 0.0001}, -1},
+		{[2]Float{0, 0}, [2]Float{0, 0}, [2]Float{3, 4}, [2]Float{-5, 0}, 0},
+		{[2]Float{0, 0}, [2]Float{0, 0}, [2]Float{3, 4}, [2]Float{-5, 0.5}, -1},
+	}
+	for _, tt := range tests {
+		if got := CompareDistanceToLine(tt.a, tt.b, tt.p, tt.q); got != tt.want {
+			t.Errorf("CompareDistanceToLine(%v, %v, %v, %v) = %d, want %d", tt.a, tt.b, tt.p, tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestCompareDistanceToLineRand(t *testing.T) {
+	for i := 0; i < 20000; i++ {
+		a := [2]Float{narrowRealRand(), narrowRealRand()}
+		b := [2]Float{narrowRealRand(), narrowRealRand()}
+		p := [2]Float{narrowRealRand(), narrowRealRand()}
+		q := [2]Float{narrowRealRand(), narrowRealRand()}
+		if i%2 == 0 {
+			// q is p mirrored through a, nearly as far from the line
+			q = [2]Float{2*a[0] - p[0], 2*a[1] - p[1]}
+		}
+		dp, dq := ratOrient2d(a, b, p), ratOrient2d(a, b, q)
+		want := new(big.Rat).Abs(dp).Cmp(new(big.Rat).Abs(dq))
+		if got := CompareDistanceToLine(a, b, p, q); got != want {
+			t.Errorf("CompareDistanceToLine(%v, %v, %v, %v) = %d, want %d", a, b, p, q, got, want)
+		}
+	}
+}
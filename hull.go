@@ -0,0 +1,83 @@
+package predicates
+
+import "sort"
+
+// ConvexHull2D returns the indices of the vertices of the convex hull of
+// points in counterclockwise order, starting at the lexicographically
+// smallest point. Points in the interior of hull edges are left out; see
+// ConvexHull2DCollinear to keep them. Of duplicate points only the first is
+// used.
+//
+// It uses Andrew's monotone chain with Orient2d deciding every turn, so
+// the hull is exact even for clouds that are nearly collinear. If all the
+// points are collinear the hull is the two extreme points, or a single
+// point if they all coincide.
+func ConvexHull2D(points [][2]Float) []int {
+	return convexHull2D(points, false)
+}
+
+// ConvexHull2DCollinear is ConvexHull2D keeping the points that lie in the
+// interior of hull edges, in order along them. If all the points are
+// collinear they are returned in lexicographic order.
+func ConvexHull2DCollinear(points [][2]Float) []int {
+	return convexHull2D(points, true)
+}
+
+func convexHull2D(points [][2]Float, collinear bool) []int {
+	order := make([]int, 0, len(points))
+	for i := range points {
+		order = append(order, i)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return lessXY(points[order[i]], points[order[j]])
+	})
+	n := 0
+	for _, i := range order {
+		if n == 0 || points[order[n-1]] != points[i] {
+			order[n] = i
+			n++
+		}
+	}
+	order = order[:n]
+	if n < 3 {
+		return order
+	}
+
+	// a turn is kept if it is to the left, or straight when collinear
+	// points are wanted
+	keep := func(a, b, c int) bool {
+		o := Orient2d(points[a], points[b], points[c])
+		return o > 0 || collinear && o == 0
+	}
+	hull := make([]int, 0, 2*n)
+	for _, i := range order {
+		for len(hull) >= 2 && !keep(hull[len(hull)-2], hull[len(hull)-1], i) {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, i)
+	}
+	lower := len(hull)
+	if lower == n && collinear && flat(points, order) {
+		return hull
+	}
+	for k := n - 2; k >= 0; k-- {
+		i := order[k]
+		for len(hull) > lower && !keep(hull[len(hull)-2], hull[len(hull)-1], i) {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, i)
+	}
+	// the first point closes the upper chain
+	return hull[:len(hull)-1]
+}
+
+// flat reports whether the points with the given indices are collinear.
+func flat(points [][2]Float, order []int) bool {
+	a, b := points[order[0]], points[order[len(order)-1]]
+	for _, i := range order[1 : len(order)-1] {
+		if Orient2d(a, b, points[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
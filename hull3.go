@@ -0,0 +1,204 @@
+package predicates
+
+// hullFace is a triangle of a 3D hull, counterclockwise seen from outside.
+// Edge i is opposite v[i], running from v[(i+1)%3] to v[(i+2)%3], and n[i]
+// is the face on the other side of it.
+type hullFace struct {
+	v    [3]int
+	n    [3]int
+	dead bool
+}
+
+// ConvexHull3D returns the boundary of the convex hull of points as
+// triangles of point indices, counterclockwise seen from outside, so that
+// Orient3d of a face and any point is positive or zero. The hull is built
+// incrementally and every visibility decision is made by Orient3d, so it
+// is exact.
+//
+// Facets with more than three vertices are returned as several coplanar
+// triangles. A point is added only if it lies strictly outside the hull of
+// the points before it, including outside a facet in its plane; points
+// inside or on the surface are left out. A point can still end up on an
+// edge or in a facet of the final hull when later points extend them. Of
+// duplicate points only the first is used. If the points are coplanar there
+// is no hull and the result is nil.
+func ConvexHull3D(points [][3]Float) [][3]int {
+	first, ok := initialTetrahedron(points)
+	if !ok {
+		return nil
+	}
+	h := hull3{points: points}
+	a, b, c, d := first[0], first[1], first[2], first[3]
+	for _, f := range [4][4]int{{a, b, c, d}, {a, b, d, c}, {a, c, d, b}, {b, c, d, a}} {
+		if Orient3d(points[f[0]], points[f[1]], points[f[2]], points[f[3]]) < 0 {
+			f[1], f[2] = f[2], f[1]
+		}
+		h.faces = append(h.faces, hullFace{v: [3]int{f[0], f[1], f[2]}})
+	}
+	h.link([]int{0, 1, 2, 3}, nil)
+	for i := range points {
+		if i != a && i != b && i != c && i != d {
+			h.add(i)
+		}
+	}
+	var faces [][3]int
+	for _, f := range h.faces {
+		if !f.dead {
+			faces = append(faces, f.v)
+		}
+	}
+	return faces
+}
+
+// initialTetrahedron returns the indices of four points that are not
+// coplanar, the first ones found in order.
+func initialTetrahedron(points [][3]Float) ([4]int, bool) {
+	var t [4]int
+	n := 0
+	for i, p := range points {
+		switch n {
+		case 1:
+			if p == points[t[0]] {
+				continue
+			}
+		case 2:
+			if Collinear3d(points[t[0]], points[t[1]], p) {
+				continue
+			}
+		case 3:
+			if Orient3d(points[t[0]], points[t[1]], points[t[2]], p) == 0 {
+				continue
+			}
+		}
+		t[n] = i
+		if n++; n == 4 {
+			return t, true
+		}
+	}
+	return t, false
+}
+
+// hull3 is a 3D convex hull under construction.
+type hull3 struct {
+	points [][3]Float
+	faces  []hullFace
+	free   []int
+}
+
+// side returns -1 if the face f must be removed to add p, because p is
+// strictly above its plane or in its plane and outside the closed triangle,
+// 0 if p is in the closed triangle, and 1 if p is strictly below the plane.
+func (h *hull3) side(f int, p [3]Float) int {
+	v := h.faces[f].v
+	a, b, c := h.points[v[0]], h.points[v[1]], h.points[v[2]]
+	if o := Orient3d(a, b, c, p); o != 0 {
+		if o < 0 {
+			return -1
+		}
+		return 1
+	}
+	// project onto a coordinate plane where the face does not degenerate
+	for k := 0; k < 3; k++ {
+		i, j := (k+1)%3, (k+2)%3
+		pa, pb, pc := [2]Float{a[i], a[j]}, [2]Float{b[i], b[j]}, [2]Float{c[i], c[j]}
+		if Orient2d(pa, pb, pc) != 0 {
+			if PointInTriangle(pa, pb, pc, [2]Float{p[i], p[j]}) == Outside {
+				return -1
+			}
+			return 0
+		}
+	}
+	panic("predicates: degenerate hull face")
+}
+
+// add inserts the point i into the hull if it is outside it.
+func (h *hull3) add(i int) {
+	p := h.points[i]
+	var gone []int
+	for f := range h.faces {
+		if h.faces[f].dead {
+			continue
+		}
+		switch h.side(f, p) {
+		case -1:
+			gone = append(gone, f)
+		case 0:
+			// on the surface
+			return
+		}
+	}
+	if len(gone) == 0 {
+		return
+	}
+	for _, f := range gone {
+		h.faces[f].dead = true
+	}
+	// a new face joins p to each edge between a removed face and a kept one
+	type horizon struct{ outer, edge int }
+	var created []int
+	var outer []horizon
+	for _, f := range gone {
+		for e := 0; e < 3; e++ {
+			g := h.faces[f].n[e]
+			if h.faces[g].dead {
+				continue
+			}
+			v := h.faces[f].v
+			created = append(created, h.newFace(v[(e+1)%3], v[(e+2)%3], i))
+			outer = append(outer, horizon{g, h.edgeTo(g, f)})
+		}
+	}
+	for _, f := range gone {
+		h.free = append(h.free, f)
+	}
+	for k, f := range created {
+		h.faces[f].n[2] = outer[k].outer
+		h.faces[outer[k].outer].n[outer[k].edge] = f
+	}
+	h.link(created, []int{0, 1})
+}
+
+// newFace allocates a face, reusing a free slot if there is one.
+func (h *hull3) newFace(a, b, c int) int {
+	f := hullFace{v: [3]int{a, b, c}}
+	if n := len(h.free); n > 0 {
+		i := h.free[n-1]
+		h.free = h.free[:n-1]
+		h.faces[i] = f
+		return i
+	}
+	h.faces = append(h.faces, f)
+	return len(h.faces) - 1
+}
+
+// edgeTo returns the index of the edge of face f shared with face g.
+func (h *hull3) edgeTo(f, g int) int {
+	for i, n := range h.faces[f].n {
+		if n == g {
+			return i
+		}
+	}
+	panic("predicates: hull faces are not adjacent")
+}
+
+// link connects the given faces to each other across their shared edges,
+// considering only the listed edge indices, or all of them if edges is nil.
+func (h *hull3) link(faces []int, edges []int) {
+	if edges == nil {
+		edges = []int{0, 1, 2}
+	}
+	open := make(map[[2]int][2]int)
+	for _, f := range faces {
+		v := h.faces[f].v
+		for _, e := range edges {
+			x, y := v[(e+1)%3], v[(e+2)%3]
+			if o, ok := open[[2]int{y, x}]; ok {
+				h.faces[f].n[e] = o[0]
+				h.faces[o[0]].n[o[1]] = f
+				delete(open, [2]int{y, x})
+			} else {
+				open[[2]int{x, y}] = [2]int{f, e}
+			}
+		}
+	}
+}
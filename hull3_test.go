@@ -0,0 +1,114 @@
+package predicates
+
+import "testing"
+
+// checkHull3 verifies that faces is a closed, outward oriented triangulated
+// surface with all the points on or inside it, and returns six times the
+// enclosed volume.
+func checkHull3(t *testing.T, points [][3]Float, faces [][3]int) Float {
+	t.Helper()
+	edges := make(map[[2]int]int)
+	vertices := make(map[int]bool)
+	for _, f := range faces {
+		a, b, c := points[f[0]], points[f[1]], points[f[2]]
+		if Collinear3d(a, b, c) {
+			t.Fatalf("face %v is degenerate", f)
+		}
+		for i, p := range points {
+			if Orient3d(a, b, c, p) < 0 {
+				t.Fatalf("point %d %v is outside face %v", i, p, f)
+			}
+		}
+		for i := 0; i < 3; i++ {
+			vertices[f[i]] = true
+			edges[[2]int{f[i], f[(i+1)%3]}]++
+		}
+	}
+	for e, n := range edges {
+		if n != 1 || edges[[2]int{e[1], e[0]}] != 1 {
+			t.Fatalf("edge %v is used %d times, its reverse %d times", e, n, edges[[2]int{e[1], e[0]}])
+		}
+	}
+	if chi := len(vertices) - len(edges)/2 + len(faces); chi != 2 {
+		t.Fatalf("Euler characteristic is %d, want 2", chi)
+	}
+	v := MeshVolume6(points, faces)
+	return Estimate(len(v), &v[0])
+}
+
+func TestConvexHull3D(t *testing.T) {
+	lattice := func(n int) [][3]Float {
+		var points [][3]Float
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				for k := 0; k < n; k++ {
+					points = append(points, [3]Float{Float(i), Float(j), Float(k)})
+				}
+			}
+		}
+		return points
+	}
+	// points on a sphere of radius 9 with exact coordinates
+	var sphere [][3]Float
+	for _, p := range [][3]Float{{9, 0, 0}, {0, 9, 0}, {0, 0, 9}, {1, 4, 8}, {4, 1, 8}, {8, 4, 1}, {4, 8, 1}, {1, 8, 4}, {8, 1, 4}} {
+		for _, s := range [][3]Float{{1, 1, 1}, {-1, 1, 1}, {1, -1, 1}, {1, 1, -1}, {-1, -1, 1}, {-1, 1, -1}, {1, -1, -1}, {-1, -1, -1}} {
+			sphere = append(sphere, [3]Float{p[0] * s[0], p[1] * s[1], p[2] * s[2]})
+		}
+	}
+	tests := []struct {
+		name   string
+		points [][3]Float
+		faces  int
+		volume Float
+	}{
+		{name: "tetrahedron", points: [][3]Float{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}, {0.1, 0.1, 0.1}}, faces: 4, volume: 1},
+		{name: "cube", points: [][3]Float{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {1, 1, 0}, {0, 0, 1}, {1, 0, 1}, {0, 1, 1}, {1, 1, 1}}, faces: 12, volume: 6},
+		{name: "duplicates", points: [][3]Float{{0, 0, 0}, {0, 0, 0}, {1, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}, {0, 0, 1}}, faces: 4, volume: 1},
+		{name: "collinear start", points: [][3]Float{{0, 0, 0}, {1, 0, 0}, {2, 0, 0}, {3, 0, 0}, {0, 1, 0}, {0, 0, 1}}, faces: 4, volume: 3},
+		{name: "lattice", points: lattice(4), volume: 6 * 27},
+		{name: "sphere", points: sphere, volume: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			faces := ConvexHull3D(tt.points)
+			volume := checkHull3(t, tt.points, faces)
+			if tt.faces > 0 && len(faces) != tt.faces {
+				t.Errorf("%d faces, want %d", len(faces), tt.faces)
+			}
+			if tt.volume > 0 && volume != tt.volume {
+				t.Errorf("volume*6 = %v, want %v", volume, tt.volume)
+			}
+		})
+	}
+}
+
+func TestConvexHull3DCoplanar(t *testing.T) {
+	for _, points := range [][][3]Float{
+		nil,
+		{{1, 2, 3}},
+		{{0, 0, 0}, {1, 1, 1}, {2, 2, 2}},
+		{{0, 0, 1}, {1, 0, 1}, {0, 1, 1}, {1, 1, 1}, {0.5, 0.5, 1}},
+	} {
+		if faces := ConvexHull3D(points); faces != nil {
+			t.Errorf("ConvexHull3D(%v) = %v, want nil", points, faces)
+		}
+	}
+}
+
+func TestConvexHull3DRandom(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		points := make([][3]Float, 200)
+		for j := range points {
+			points[j] = [3]Float{narrowRealRand(), narrowRealRand(), narrowRealRand()}
+		}
+		checkHull3(t, points, ConvexHull3D(points))
+	}
+	// coarse lattice points give many coplanar facets
+	for i := 0; i < 20; i++ {
+		points := make([][3]Float, 200)
+		for j := range points {
+			points[j] = [3]Float{Float(random() % 5), Float(random() % 5), Float(random() % 5)}
+		}
+		checkHull3(t, points, ConvexHull3D(points))
+	}
+}
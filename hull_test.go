@@ -0,0 +1,100 @@
+package predicates
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// checkHull verifies that hull is a convex counterclockwise polygon with
+// all the points on or inside it, and that it has no straight turns unless
+// collinear is set.
+func checkHull(t *testing.T, points [][2]Float, hull []int, collinear bool) {
+	t.Helper()
+	n := len(hull)
+	if n < 3 {
+		return
+	}
+	for k := 0; k < n; k++ {
+		a, b, c := points[hull[k]], points[hull[(k+1)%n]], points[hull[(k+2)%n]]
+		if o := Orient2d(a, b, c); o < 0 || o == 0 && !collinear {
+			t.Fatalf("turn at hull vertex %d has orientation %v", hull[(k+1)%n], o)
+		}
+		for i, p := range points {
+			if Orient2d(a, b, p) < 0 {
+				t.Fatalf("point %d %v is outside hull edge %v %v", i, p, a, b)
+			}
+		}
+	}
+}
+
+func TestConvexHull2D(t *testing.T) {
+	tests := []struct {
+		name      string
+		points    [][2]Float
+		hull      []int
+		collinear []int
+	}{
+		{name: "empty", points: nil, hull: []int{}, collinear: []int{}},
+		{name: "single", points: [][2]Float{{1, 2}}, hull: []int{0}, collinear: []int{0}},
+		{name: "coincident", points: [][2]Float{{1, 2}, {1, 2}, {1, 2}}, hull: []int{0}, collinear: []int{0}},
+		{name: "collinear", points: [][2]Float{{2, 2}, {0, 0}, {3, 3}, {1, 1}}, hull: []int{1, 2}, collinear: []int{1, 3, 0, 2}},
+		{
+			name:      "square",
+			points:    [][2]Float{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {1, 1}, {1, 0}, {2, 1}, {0, 0}},
+			hull:      []int{0, 1, 2, 3},
+			collinear: []int{0, 5, 1, 6, 2, 3},
+		},
+		{
+			name:      "triangle",
+			points:    [][2]Float{{0, 0}, {4, 4}, {1, 1}, {4, 0}, {2, 1}, {3, 3}},
+			hull:      []int{0, 3, 1},
+			collinear: []int{0, 3, 1, 5, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConvexHull2D(tt.points); !reflect.DeepEqual(got, tt.hull) {
+				t.Errorf("ConvexHull2D() = %v, want %v", got, tt.hull)
+			}
+			if got := ConvexHull2DCollinear(tt.points); !reflect.DeepEqual(got, tt.collinear) {
+				t.Errorf("ConvexHull2DCollinear() = %v, want %v", got, tt.collinear)
+			}
+		})
+	}
+}
+
+func TestConvexHull2DNearlyCollinear(t *testing.T) {
+	// points on the line y = x/3, rounded, and their neighbours one unit
+	// in the last place above and below it
+	var points [][2]Float
+	for i := 0; i < 200; i++ {
+		x := Float(i) * 0.37
+		y := x / 3
+		points = append(points, [2]Float{x, y})
+		switch i % 3 {
+		case 1:
+			points = append(points, [2]Float{x, math.Nextafter32(y, 1e9)})
+		case 2:
+			points = append(points, [2]Float{x, math.Nextafter32(y, -1e9)})
+		}
+	}
+	checkHull(t, points, ConvexHull2D(points), false)
+	checkHull(t, points, ConvexHull2DCollinear(points), true)
+}
+
+func TestConvexHull2DRandom(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		points := make([][2]Float, 50)
+		for j := range points {
+			points[j] = [2]Float{narrowRealRand(), narrowRealRand()}
+		}
+		hull := ConvexHull2D(points)
+		checkHull(t, points, hull, false)
+		all := ConvexHull2DCollinear(points)
+		checkHull(t, points, all, true)
+		if len(all) < len(hull) {
+			t.Fatalf("%d hull points with collinear ones, %d without", len(all), len(hull))
+		}
+	}
+}
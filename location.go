@@ -0,0 +1,262 @@
+package predicates
+
+// Location describes where a point lies relative to a simplex or polygon.
+type Location int
+
+const (
+	// Outside means the point is not in the closed region.
+	Outside Location = iota
+	// Inside means the point is in the open interior of the region.
+	Inside
+	// OnVertex means the point coincides with a vertex.
+	OnVertex
+	// OnEdge means the point lies in the relative interior of an edge.
+	OnEdge
+	// OnFace means the point lies in the relative interior of a triangular
+	// face of a tetrahedron.
+	OnFace
+)
+
+func (l Location) String() string {
+	switch l {
+	case Outside:
+		return "Outside"
+	case Inside:
+		return "Inside"
+	case OnVertex:
+		return "OnVertex"
+	case OnEdge:
+		return "OnEdge"
+	case OnFace:
+		return "OnFace"
+	}
+	return "Location(?)"
+}
+
+// orient2dDiff is Orient2d(pa, pb, pc) where the differences pa-pc and pb-pc
+// have already been computed by the caller.
+func orient2dDiff(pa, pb, pc [2]Float, acx, acy, bcx, bcy Float) Float {
+	var detleft, detright, det Float
+	var detsum, errbound Float
+
+	detleft = acx * bcy
+	detright = acy * bcx
+	det = detleft - detright
+
+	if detleft > 0.0 {
+		if detright <= 0.0 {
+			return det
+		}
+		detsum = detleft + detright
+	} else if detleft < 0.0 {
+		if detright >= 0.0 {
+			return det
+		}
+		detsum = -detleft - detright
+	} else {
+		return det
+	}
+
+	errbound = ccwerrboundA * detsum
+	if (det >= errbound) || (-det >= errbound) {
+		return det
+	}
+	return Orient2dAdapt(pa, pb, pc, detsum)
+}
+
+// orient3dDiff is Orient3d(pa, pb, pc, pd) where the differences pa-pd, pb-pd
+// and pc-pd have already been computed by the caller.
+func orient3dDiff(pa, pb, pc, pd [3]Float, ad, bd, cd [3]Float) Float {
+	var bdxcdy, cdxbdy, cdxady, adxcdy, adxbdy, bdxady Float
+	var det Float
+	var permanent, errbound Float
+
+	bdxcdy = bd[0] * cd[1]
+	cdxbdy = cd[0] * bd[1]
+
+	cdxady = cd[0] * ad[1]
+	adxcdy = ad[0] * cd[1]
+
+	adxbdy = ad[0] * bd[1]
+	bdxady = bd[0] * ad[1]
+
+	det = ad[2]*(bdxcdy-cdxbdy) +
+		bd[2]*(cdxady-adxcdy) +
+		cd[2]*(adxbdy-bdxady)
+
+	permanent = (abs(bdxcdy)+abs(cdxbdy))*abs(ad[2]) +
+		(abs(cdxady)+abs(adxcdy))*abs(bd[2]) +
+		(abs(adxbdy)+abs(bdxady))*abs(cd[2])
+
+	errbound = o3derrboundA * permanent
+	if (det > errbound) || (-det > errbound) {
+		return det
+	}
+	return Orient3dAdapt(pa, pb, pc, pd, permanent)
+}
+
+// classifyBarycentric turns the signs of the sub-simplex determinants of a
+// positively oriented simplex into a Location. boundary lists the location
+// reported for one, two, three... vanishing determinants.
+func classifyBarycentric(dets []Float, boundary ...Location) Location {
+	zeros := 0
+	for _, d := range dets {
+		if d < 0 {
+			return Outside
+		}
+		if d == 0 {
+			zeros++
+		}
+	}
+	if zeros == 0 {
+		return Inside
+	}
+	return boundary[zeros-1]
+}
+
+// PointInTriangle locates p relative to the closed triangle abc, which may be
+// in either orientation. The three orientation tests share the differences
+// a-p, b-p and c-p and only fall back to exact arithmetic when the error
+// bound of Orient2d says they must.
+//
+// A degenerate (collinear) triangle is treated as the union of its edges, so
+// the result is OnVertex, OnEdge or Outside.
+func PointInTriangle(a, b, c, p [2]Float) Location {
+	if p == a || p == b || p == c {
+		return OnVertex
+	}
+	o := Orient2d(a, b, c)
+	if o == 0 {
+		if a != b && onSegment(a, b, p) || b != c && onSegment(b, c, p) || c != a && onSegment(c, a, p) {
+			return OnEdge
+		}
+		return Outside
+	}
+
+	apx, apy := a[0]-p[0], a[1]-p[1]
+	bpx, bpy := b[0]-p[0], b[1]-p[1]
+	cpx, cpy := c[0]-p[0], c[1]-p[1]
+	dets := [3]Float{
+		orient2dDiff(a, b, p, apx, apy, bpx, bpy),
+		orient2dDiff(b, c, p, bpx, bpy, cpx, cpy),
+		orient2dDiff(c, a, p, cpx, cpy, apx, apy),
+	}
+	if o < 0 {
+		for i := range dets {
+			dets[i] = -dets[i]
+		}
+	}
+	return classifyBarycentric(dets[:], OnEdge, OnVertex)
+}
+
+// Collinear3d reports whether the points a, b and c lie on a common line in
+// 3D, exactly: it checks with Orient2d that their projections onto the
+// three coordinate planes are all collinear.
+func Collinear3d(a, b, c [3]Float) bool {
+	return Orient2d([2]Float{a[0], a[1]}, [2]Float{b[0], b[1]}, [2]Float{c[0], c[1]}) == 0 &&
+		Orient2d([2]Float{a[1], a[2]}, [2]Float{b[1], b[2]}, [2]Float{c[1], c[2]}) == 0 &&
+		Orient2d([2]Float{a[2], a[0]}, [2]Float{b[2], b[0]}, [2]Float{c[2], c[0]}) == 0
+}
+
+// lessXYZ orders points lexicographically, which along a line in 3D is the
+// order of the points on the line.
+func lessXYZ(a, b [3]Float) bool {
+	if a[0] != b[0] {
+		return a[0] < b[0]
+	}
+	if a[1] != b[1] {
+		return a[1] < b[1]
+	}
+	return a[2] < b[2]
+}
+
+// onSegment3 reports whether p lies on the closed segment ab in 3D. a and b
+// must be distinct.
+func onSegment3(a, b, p [3]Float) bool {
+	if !Collinear3d(a, b, p) {
+		return false
+	}
+	if lessXYZ(b, a) {
+		a, b = b, a
+	}
+	return !lessXYZ(p, a) && !lessXYZ(b, p)
+}
+
+// PointInTetrahedron locates p relative to the closed tetrahedron abcd, which
+// may be in either orientation. The four orientation tests share the
+// differences a-p, b-p, c-p and d-p and only fall back to exact arithmetic
+// when the error bound of Orient3d says they must.
+//
+// A degenerate (coplanar) tetrahedron is treated as the flat region it
+// covers, the union of its faces: a point in it is OnFace, or OnEdge if it
+// is on one of the six edges, including the ones crossing the region. If
+// the four points are collinear too, only OnEdge is possible.
+func PointInTetrahedron(a, b, c, d, p [3]Float) Location {
+	if p == a || p == b || p == c || p == d {
+		return OnVertex
+	}
+	o := Orient3d(a, b, c, d)
+	if o == 0 {
+		return pointInFlatTetrahedron([4][3]Float{a, b, c, d}, p)
+	}
+
+	var ap, bp, cp, dp [3]Float
+	for i := 0; i < 3; i++ {
+		ap[i] = a[i] - p[i]
+		bp[i] = b[i] - p[i]
+		cp[i] = c[i] - p[i]
+		dp[i] = d[i] - p[i]
+	}
+	// replacing each vertex of abcd by p in turn, with p moved to the last
+	// position of Orient3d; the sign follows the parity of that permutation.
+	dets := [4]Float{
+		-orient3dDiff(b, c, d, p, bp, cp, dp),
+		orient3dDiff(a, c, d, p, ap, cp, dp),
+		-orient3dDiff(a, b, d, p, ap, bp, dp),
+		orient3dDiff(a, b, c, p, ap, bp, cp),
+	}
+	if o < 0 {
+		for i := range dets {
+			dets[i] = -dets[i]
+		}
+	}
+	return classifyBarycentric(dets[:], OnFace, OnEdge, OnVertex)
+}
+
+// pointInFlatTetrahedron is PointInTetrahedron for coplanar vertices v.
+// Once p is known to be on their plane, the faces are located with
+// PointInTriangle in a projection that keeps one of them non-degenerate,
+// which maps the plane one to one onto the coordinate plane.
+func pointInFlatTetrahedron(v [4][3]Float, p [3]Float) Location {
+	faces := [4][3]int{{1, 2, 3}, {0, 2, 3}, {0, 1, 3}, {0, 1, 2}}
+	for _, f := range faces {
+		a, b, c := v[f[0]], v[f[1]], v[f[2]]
+		k := dropAxis(a, b, c)
+		if k < 0 {
+			continue
+		}
+		if Orient3d(a, b, c, p) != 0 {
+			return Outside
+		}
+		q := project2(p, k)
+		loc := Outside
+		for _, g := range faces {
+			switch PointInTriangle(project2(v[g[0]], k), project2(v[g[1]], k), project2(v[g[2]], k), q) {
+			case OnEdge:
+				return OnEdge
+			case Inside:
+				loc = OnFace
+			}
+		}
+		return loc
+	}
+	// all four points are collinear
+	for i := 0; i < 4; i++ {
+		for j := i + 1; j < 4; j++ {
+			if v[i] != v[j] && onSegment3(v[i], v[j], p) {
+				return OnEdge
+			}
+		}
+	}
+	return Outside
+}
@@ -0,0 +1,139 @@
+package predicates
+
+import "testing"
+
+func TestPointInTriangle(t *testing.T) {
+	a, b, c := [2]Float{0, 0}, [2]Float{4, 0}, [2]Float{0, 4}
+	tests := []struct {
+		name string
+		p    [2]Float
+		want Location
+	}{
+		{name: "inside", p: [2]Float{1, 1}, want: Inside},
+		{name: "outside", p: [2]Float{3, 3}, want: Outside},
+		{name: "on hypotenuse", p: [2]Float{2, 2}, want: OnEdge},
+		{name: "on leg", p: [2]Float{0, 1}, want: OnEdge},
+		{name: "on vertex", p: [2]Float{4, 0}, want: OnVertex},
+		{name: "on edge line beyond vertex", p: [2]Float{5, 0}, want: Outside},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PointInTriangle(a, b, c, tt.p); got != tt.want {
+				t.Errorf("PointInTriangle() = %v, want %v", got, tt.want)
+			}
+			if got := PointInTriangle(a, c, b, tt.p); got != tt.want {
+				t.Errorf("PointInTriangle() clockwise = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPointInTriangleDegenerate(t *testing.T) {
+	a, b, c := [2]Float{0, 0}, [2]Float{1, 1}, [2]Float{3, 3}
+	if got := PointInTriangle(a, b, c, [2]Float{2, 2}); got != OnEdge {
+		t.Errorf("PointInTriangle() = %v, want %v", got, OnEdge)
+	}
+	if got := PointInTriangle(a, b, c, [2]Float{2, 1}); got != Outside {
+		t.Errorf("PointInTriangle() = %v, want %v", got, Outside)
+	}
+}
+
+func TestPointInTriangleRand(t *testing.T) {
+	for i := 0; i < 10000; i++ {
+		a := [2]Float{narrowRealRand(), narrowRealRand()}
+		b := [2]Float{narrowRealRand(), narrowRealRand()}
+		c := [2]Float{narrowRealRand(), narrowRealRand()}
+		p := [2]Float{narrowRealRand(), narrowRealRand()}
+		o := Orient2dExact(a, b, c)
+		o1, o2, o3 := Orient2dExact(a, b, p), Orient2dExact(b, c, p), Orient2dExact(c, a, p)
+		want := Outside
+		if o > 0 && o1 > 0 && o2 > 0 && o3 > 0 || o < 0 && o1 < 0 && o2 < 0 && o3 < 0 {
+			want = Inside
+		}
+		if got := PointInTriangle(a, b, c, p); (got == Inside) != (want == Inside) {
+			t.Errorf("PointInTriangle() = %v, want %v, a=%v, b=%v, c=%v, p=%v", got, want, a, b, c, p)
+		}
+	}
+}
+
+func TestPointInTetrahedron(t *testing.T) {
+	a, b, c, d := [3]Float{0, 0, 0}, [3]Float{4, 0, 0}, [3]Float{0, 4, 0}, [3]Float{0, 0, 4}
+	tests := []struct {
+		name string
+		p    [3]Float
+		want Location
+	}{
+		{name: "inside", p: [3]Float{1, 1, 1}, want: Inside},
+		{name: "outside", p: [3]Float{2, 2, 2}, want: Outside},
+		{name: "below", p: [3]Float{1, 1, -1}, want: Outside},
+		{name: "on slanted face", p: [3]Float{2, 1, 1}, want: OnFace},
+		{name: "on base face", p: [3]Float{1, 1, 0}, want: OnFace},
+		{name: "on edge", p: [3]Float{2, 0, 0}, want: OnEdge},
+		{name: "on slanted edge", p: [3]Float{2, 2, 0}, want: OnEdge},
+		{name: "on vertex", p: [3]Float{0, 0, 4}, want: OnVertex},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PointInTetrahedron(a, b, c, d, tt.p); got != tt.want {
+				t.Errorf("PointInTetrahedron() = %v, want %v", got, tt.want)
+			}
+			if got := PointInTetrahedron(b, a, c, d, tt.p); got != tt.want {
+				t.Errorf("PointInTetrahedron() reversed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPointInTetrahedronDegenerate(t *testing.T) {
+	tests := []struct {
+		name       string
+		a, b, c, d [3]Float
+		p          [3]Float
+		want       Location
+	}{
+		// a flat square, with the diagonals ad and bc as edges
+		{"crossing of diagonals", [3]Float{0, 0, 0}, [3]Float{4, 0, 0}, [3]Float{0, 4, 0}, [3]Float{4, 4, 0}, [3]Float{2, 2, 0}, OnEdge},
+		{"on diagonal", [3]Float{0, 0, 0}, [3]Float{4, 0, 0}, [3]Float{0, 4, 0}, [3]Float{4, 4, 0}, [3]Float{1, 1, 0}, OnEdge},
+		{"in square", [3]Float{0, 0, 0}, [3]Float{4, 0, 0}, [3]Float{0, 4, 0}, [3]Float{4, 4, 0}, [3]Float{1, 2, 0}, OnFace},
+		{"on side", [3]Float{0, 0, 0}, [3]Float{4, 0, 0}, [3]Float{0, 4, 0}, [3]Float{4, 4, 0}, [3]Float{4, 1, 0}, OnEdge},
+		{"beside square", [3]Float{0, 0, 0}, [3]Float{4, 0, 0}, [3]Float{0, 4, 0}, [3]Float{4, 4, 0}, [3]Float{5, 2, 0}, Outside},
+		{"above square", [3]Float{0, 0, 0}, [3]Float{4, 0, 0}, [3]Float{0, 4, 0}, [3]Float{4, 4, 0}, [3]Float{1, 2, 1}, Outside},
+		// a slanted flat triangle with d inside it, projecting to a
+		// segment on the yz plane
+		{"in slanted triangle", [3]Float{0, 0, 0}, [3]Float{6, 0, 6}, [3]Float{0, 6, 0}, [3]Float{2, 2, 2}, [3]Float{1, 3, 1}, OnFace},
+		{"on inner edge", [3]Float{0, 0, 0}, [3]Float{6, 0, 6}, [3]Float{0, 6, 0}, [3]Float{2, 2, 2}, [3]Float{1, 1, 1}, OnEdge},
+		{"off slanted plane", [3]Float{0, 0, 0}, [3]Float{6, 0, 6}, [3]Float{0, 6, 0}, [3]Float{2, 2, 2}, [3]Float{1, 3, 2}, Outside},
+		// all four collinear
+		{"on collinear", [3]Float{0, 0, 0}, [3]Float{1, 1, 1}, [3]Float{3, 3, 3}, [3]Float{2, 2, 2}, [3]Float{2.5, 2.5, 2.5}, OnEdge},
+		{"off collinear", [3]Float{0, 0, 0}, [3]Float{1, 1, 1}, [3]Float{3, 3, 3}, [3]Float{2, 2, 2}, [3]Float{4, 4, 4}, Outside},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PointInTetrahedron(tt.a, tt.b, tt.c, tt.d, tt.p); got != tt.want {
+				t.Errorf("PointInTetrahedron() = %v, want %v", got, tt.want)
+			}
+			if got := PointInTetrahedron(tt.d, tt.c, tt.b, tt.a, tt.p); got != tt.want {
+				t.Errorf("PointInTetrahedron() reversed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollinear3d(t *testing.T) {
+	tests := []struct {
+		a, b, c [3]Float
+		want    bool
+	}{
+		{[3]Float{0, 0, 0}, [3]Float{1, 2, 3}, [3]Float{2, 4, 6}, true},
+		{[3]Float{0, 0, 0}, [3]Float{1, 2, 3}, [3]Float{2, 4, 7}, false},
+		// collinear in the xy projection only
+		{[3]Float{0, 0, 0}, [3]Float{1, 1, 0}, [3]Float{2, 2, 5}, false},
+		{[3]Float{1, 1, 1}, [3]Float{1, 1, 1}, [3]Float{5, -3, 2}, true},
+		{[3]Float{0, 0, 0}, [3]Float{0, 0, 1}, [3]Float{0, 0, -7}, true},
+	}
+	for _, tt := range tests {
+		if got := Collinear3d(tt.a, tt.b, tt.c); got != tt.want {
+			t.Errorf("Collinear3d(%v, %v, %v) = %v, want %v", tt.a, tt.b, tt.c, got, tt.want)
+		}
+	}
+}
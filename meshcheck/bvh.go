@@ -0,0 +1,153 @@
+package meshcheck
+
+import "sort"
+
+// box is a closed axis-aligned bounding box.
+type box struct {
+	lo, hi [3]Float
+}
+
+func boundTriangle(a, b, c [3]Float) box {
+	bb := box{a, a}
+	bb.add(b)
+	bb.add(c)
+	return bb
+}
+
+func (b *box) add(p [3]Float) {
+	for k := 0; k < 3; k++ {
+		if p[k] < b.lo[k] {
+			b.lo[k] = p[k]
+		}
+		if p[k] > b.hi[k] {
+			b.hi[k] = p[k]
+		}
+	}
+}
+
+func (b *box) union(c box) {
+	b.add(c.lo)
+	b.add(c.hi)
+}
+
+// overlaps reports whether the closed boxes have a point in common, so
+// boxes that only touch are candidates too.
+func (b *box) overlaps(c *box) bool {
+	for k := 0; k < 3; k++ {
+		if b.hi[k] < c.lo[k] || c.hi[k] < b.lo[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// leafSize is the largest number of items in a leaf of the hierarchy.
+const leafSize = 4
+
+// bvhNode is a node of a bounding volume hierarchy. A leaf holds the items
+// from start to end, an inner node has two children.
+type bvhNode struct {
+	box         box
+	left, right int
+	start, end  int
+}
+
+// bvh is a bounding volume hierarchy over a set of boxes, split at the
+// median of the longest axis.
+type bvh struct {
+	boxes []box
+	items []int
+	nodes []bvhNode
+}
+
+func newBVH(boxes []box) *bvh {
+	h := &bvh{boxes: boxes, items: make([]int, len(boxes))}
+	for i := range h.items {
+		h.items[i] = i
+	}
+	if len(boxes) > 0 {
+		h.build(0, len(boxes))
+	}
+	return h
+}
+
+// build adds the node for the items from start to end and returns its
+// index.
+func (h *bvh) build(start, end int) int {
+	n := len(h.nodes)
+	h.nodes = append(h.nodes, bvhNode{left: -1, right: -1, start: start, end: end})
+	bb := h.boxes[h.items[start]]
+	for _, i := range h.items[start+1 : end] {
+		bb.union(h.boxes[i])
+	}
+	h.nodes[n].box = bb
+	if end-start <= leafSize {
+		return n
+	}
+	axis := 0
+	for k := 1; k < 3; k++ {
+		if float64(bb.hi[k])-float64(bb.lo[k]) > float64(bb.hi[axis])-float64(bb.lo[axis]) {
+			axis = k
+		}
+	}
+	items := h.items[start:end]
+	sort.Slice(items, func(i, j int) bool {
+		a, b := &h.boxes[items[i]], &h.boxes[items[j]]
+		return float64(a.lo[axis])+float64(a.hi[axis]) < float64(b.lo[axis])+float64(b.hi[axis])
+	})
+	mid := (start + end) / 2
+	left := h.build(start, mid)
+	right := h.build(mid, end)
+	h.nodes[n].left, h.nodes[n].right = left, right
+	return n
+}
+
+// pairs calls f once for every pair of distinct items whose boxes overlap.
+func (h *bvh) pairs(f func(i, j int)) {
+	if len(h.nodes) > 0 {
+		h.self(0, f)
+	}
+}
+
+// self visits the pairs within the node n.
+func (h *bvh) self(n int, f func(i, j int)) {
+	node := &h.nodes[n]
+	if node.left < 0 {
+		items := h.items[node.start:node.end]
+		for a, i := range items {
+			for _, j := range items[a+1:] {
+				if h.boxes[i].overlaps(&h.boxes[j]) {
+					f(i, j)
+				}
+			}
+		}
+		return
+	}
+	h.self(node.left, f)
+	h.self(node.right, f)
+	h.cross(node.left, node.right, f)
+}
+
+// cross visits the pairs with one item in the node m and one in n.
+func (h *bvh) cross(m, n int, f func(i, j int)) {
+	a, b := &h.nodes[m], &h.nodes[n]
+	if !a.box.overlaps(&b.box) {
+		return
+	}
+	switch {
+	case a.left < 0 && b.left < 0:
+		for _, i := range h.items[a.start:a.end] {
+			for _, j := range h.items[b.start:b.end] {
+				if h.boxes[i].overlaps(&h.boxes[j]) {
+					f(i, j)
+				}
+			}
+		}
+	case b.left < 0 || a.left >= 0 && a.end-a.start >= b.end-b.start:
+		h.cross(a.left, n, f)
+		h.cross(a.right, n, f)
+	default:
+		h.cross(m, b.left, f)
+		h.cross(m, b.right, f)
+	}
+}
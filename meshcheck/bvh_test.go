@@ -0,0 +1,43 @@
+package meshcheck
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestBVHPairs(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{0, 1, 2, 5, 50, 300} {
+		boxes := make([]box, n)
+		for i := range boxes {
+			var p, q [3]Float
+			for k := 0; k < 3; k++ {
+				// coarse coordinates make boxes that only touch
+				p[k] = Float(r.Intn(20))
+				q[k] = p[k] + Float(r.Intn(3))
+			}
+			boxes[i] = box{p, q}
+		}
+		got := make(map[[2]int]int)
+		newBVH(boxes).pairs(func(i, j int) {
+			if j < i {
+				i, j = j, i
+			}
+			got[[2]int{i, j}]++
+		})
+		want := 0
+		for i := range boxes {
+			for j := i + 1; j < n; j++ {
+				if boxes[i].overlaps(&boxes[j]) {
+					want++
+					if got[[2]int{i, j}] != 1 {
+						t.Fatalf("n=%d: pair %d, %d visited %d times", n, i, j, got[[2]int{i, j}])
+					}
+				}
+			}
+		}
+		if len(got) != want {
+			t.Errorf("n=%d: %d pairs, want %d", n, len(got), want)
+		}
+	}
+}
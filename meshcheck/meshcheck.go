@@ -0,0 +1,184 @@
+// Package meshcheck finds defects in indexed meshes: self-intersecting
+// triangles, degenerate elements, inverted tetrahedra and non-manifold
+// edges and faces.
+//
+// Candidate pairs of triangles come from a bounding volume hierarchy over
+// their boxes, which are exact because the vertices are Float, and every
+// reported defect is decided by the exact predicates of the parent package.
+package meshcheck
+
+import (
+	"sort"
+
+	"github.com/toy80/predicates"
+)
+
+// Float is the floating point type of the coordinates.
+type Float = predicates.Float
+
+// Intersection is a pair of triangles that meet where they should not.
+type Intersection struct {
+	// A and B are the triangle indices, A < B.
+	A, B    int
+	Kind    predicates.IntersectionKind
+	Contact predicates.Contact
+}
+
+// Report lists the defects of a triangle mesh.
+type Report struct {
+	// Intersections are the pairs of non-degenerate triangles that meet
+	// anywhere other than their shared vertices or their shared edge.
+	Intersections []Intersection
+	// Degenerate are the triangles whose vertices are collinear, including
+	// those with repeated vertex indices.
+	Degenerate []int
+	// NonManifold are the edges, as vertex indices with the smaller first,
+	// used by more than two triangles.
+	NonManifold [][2]int
+}
+
+// OK reports whether no defect was found.
+func (r *Report) OK() bool {
+	return len(r.Intersections) == 0 && len(r.Degenerate) == 0 && len(r.NonManifold) == 0
+}
+
+// Triangles checks the mesh of triangles over points. Triangles that share
+// one vertex must meet only there, and triangles that share two must meet
+// only along that edge; other pairs must not meet at all. Coincident points
+// with different indices are not shared vertices, so triangles meeting at
+// them are reported.
+func Triangles(points [][3]Float, triangles [][3]int) *Report {
+	r := &Report{}
+	var valid []int
+	edges := make(map[[2]int]int)
+	for t, tri := range triangles {
+		if predicates.Collinear3d(points[tri[0]], points[tri[1]], points[tri[2]]) {
+			r.Degenerate = append(r.Degenerate, t)
+		} else {
+			valid = append(valid, t)
+		}
+		var seen [3][2]int
+		for i := 0; i < 3; i++ {
+			a, b := tri[i], tri[(i+1)%3]
+			if b < a {
+				a, b = b, a
+			}
+			seen[i] = [2]int{a, b}
+			// a triangle with a repeated vertex uses its one edge once
+			if a != b && (i == 0 || seen[i] != seen[0]) && (i < 2 || seen[i] != seen[1]) {
+				edges[seen[i]]++
+			}
+		}
+	}
+	for e, n := range edges {
+		if n > 2 {
+			r.NonManifold = append(r.NonManifold, e)
+		}
+	}
+	sort.Slice(r.NonManifold, func(i, j int) bool {
+		a, b := r.NonManifold[i], r.NonManifold[j]
+		return a[0] < b[0] || a[0] == b[0] && a[1] < b[1]
+	})
+
+	boxes := make([]box, len(valid))
+	for i, t := range valid {
+		tri := triangles[t]
+		boxes[i] = boundTriangle(points[tri[0]], points[tri[1]], points[tri[2]])
+	}
+	h := newBVH(boxes)
+	h.pairs(func(i, j int) {
+		a, b := valid[i], valid[j]
+		if b < a {
+			a, b = b, a
+		}
+		ta, tb := triangles[a], triangles[b]
+		p := [3][3]Float{points[ta[0]], points[ta[1]], points[ta[2]]}
+		q := [3][3]Float{points[tb[0]], points[tb[1]], points[tb[2]]}
+		kind, contact := predicates.TrianglesIntersect(p, q)
+		if kind == predicates.Disjoint {
+			return
+		}
+		switch shared(ta, tb) {
+		case 1:
+			if kind == predicates.Touching && contact == predicates.PointContact {
+				return
+			}
+		case 2:
+			if kind == predicates.Touching && contact == predicates.SegmentContact {
+				return
+			}
+		}
+		r.Intersections = append(r.Intersections, Intersection{a, b, kind, contact})
+	})
+	sort.Slice(r.Intersections, func(i, j int) bool {
+		a, b := r.Intersections[i], r.Intersections[j]
+		return a.A < b.A || a.A == b.A && a.B < b.B
+	})
+	return r
+}
+
+// TetReport lists the defects of a tetrahedral mesh.
+type TetReport struct {
+	// Inverted are the tetrahedra abcd with Orient3d(a, b, c, d) < 0.
+	Inverted []int
+	// Flat are the tetrahedra whose vertices are coplanar.
+	Flat []int
+	// NonManifold are the faces, as vertex indices in increasing order,
+	// used by more than two tetrahedra.
+	NonManifold [][3]int
+}
+
+// OK reports whether no defect was found.
+func (r *TetReport) OK() bool {
+	return len(r.Inverted) == 0 && len(r.Flat) == 0 && len(r.NonManifold) == 0
+}
+
+// Tetrahedra checks the mesh of tetrahedra over points. A tetrahedron abcd
+// is positively oriented if Orient3d(a, b, c, d) > 0.
+func Tetrahedra(points [][3]Float, tets [][4]int) *TetReport {
+	r := &TetReport{}
+	faces := make(map[[3]int]int)
+	for t, tet := range tets {
+		o := predicates.Orient3d(points[tet[0]], points[tet[1]], points[tet[2]], points[tet[3]])
+		switch {
+		case o < 0:
+			r.Inverted = append(r.Inverted, t)
+		case o == 0:
+			r.Flat = append(r.Flat, t)
+		}
+		for i := 0; i < 4; i++ {
+			f := [3]int{tet[(i+1)%4], tet[(i+2)%4], tet[(i+3)%4]}
+			sort.Ints(f[:])
+			faces[f]++
+		}
+	}
+	for f, n := range faces {
+		if n > 2 {
+			r.NonManifold = append(r.NonManifold, f)
+		}
+	}
+	sort.Slice(r.NonManifold, func(i, j int) bool {
+		a, b := r.NonManifold[i], r.NonManifold[j]
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return false
+	})
+	return r
+}
+
+// shared returns the number of vertex indices the triangles have in common.
+func shared(a, b [3]int) int {
+	n := 0
+	for _, u := range a {
+		for _, v := range b {
+			if u == v {
+				n++
+				break
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,171 @@
+package meshcheck
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/toy80/predicates"
+)
+
+// octahedron returns a closed surface around center.
+func octahedron(center [3]Float, r Float) ([][3]Float, [][3]int) {
+	c := center
+	points := [][3]Float{
+		{c[0] + r, c[1], c[2]}, {c[0] - r, c[1], c[2]},
+		{c[0], c[1] + r, c[2]}, {c[0], c[1] - r, c[2]},
+		{c[0], c[1], c[2] + r}, {c[0], c[1], c[2] - r},
+	}
+	triangles := [][3]int{
+		{0, 2, 4}, {2, 1, 4}, {1, 3, 4}, {3, 0, 4},
+		{2, 0, 5}, {1, 2, 5}, {3, 1, 5}, {0, 3, 5},
+	}
+	return points, triangles
+}
+
+// merge appends the second mesh to the first.
+func merge(p1 [][3]Float, t1 [][3]int, p2 [][3]Float, t2 [][3]int) ([][3]Float, [][3]int) {
+	n := len(p1)
+	points := append(append([][3]Float(nil), p1...), p2...)
+	triangles := append([][3]int(nil), t1...)
+	for _, t := range t2 {
+		triangles = append(triangles, [3]int{t[0] + n, t[1] + n, t[2] + n})
+	}
+	return points, triangles
+}
+
+func TestTriangles(t *testing.T) {
+	op, ot := octahedron([3]Float{0, 0, 0}, 2)
+	apart := func() ([][3]Float, [][3]int) {
+		p, q := octahedron([3]Float{5, 0, 0}, 2)
+		return merge(op, ot, p, q)
+	}
+	overlap := func() ([][3]Float, [][3]int) {
+		p, q := octahedron([3]Float{1, 1, 1}, 2)
+		return merge(op, ot, p, q)
+	}
+	kissing := func() ([][3]Float, [][3]int) {
+		// the second octahedron touches the first at the point (2, 0, 0)
+		p, q := octahedron([3]Float{4, 0, 0}, 2)
+		return merge(op, ot, p, q)
+	}
+	fin := func() ([][3]Float, [][3]int) {
+		// a third triangle on the edge from 0 to 2, outside the surface
+		return append(op, [3]Float{3, 3, 0}), append(ot, [3]int{0, 2, 6})
+	}
+	degenerate := func() ([][3]Float, [][3]int) {
+		return append(op, [3]Float{4, 0, 0}), append(ot, [3]int{0, 0, 6}, [3]int{0, 1, 6})
+	}
+	fold := func() ([][3]Float, [][3]int) {
+		// two coplanar triangles on the same side of their shared edge
+		return [][3]Float{{0, 0, 0}, {4, 0, 0}, {0, 4, 0}, {1, 1, 0}}, [][3]int{{0, 1, 2}, {1, 0, 3}}
+	}
+	tests := []struct {
+		name          string
+		mesh          func() ([][3]Float, [][3]int)
+		intersections int
+		degenerate    int
+		nonManifold   int
+	}{
+		{name: "closed", mesh: func() ([][3]Float, [][3]int) { return op, ot }},
+		{name: "apart", mesh: apart},
+		{name: "overlap", mesh: overlap, intersections: 12},
+		{name: "kissing", mesh: kissing, intersections: 16},
+		{name: "fin", mesh: fin, nonManifold: 1},
+		{name: "degenerate", mesh: degenerate, degenerate: 2},
+		{name: "fold", mesh: fold, intersections: 1},
+		{name: "duplicate", mesh: func() ([][3]Float, [][3]int) { return op, append(ot, ot[0]) }, intersections: 1, nonManifold: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points, triangles := tt.mesh()
+			r := Triangles(points, triangles)
+			if len(r.Intersections) != tt.intersections || len(r.Degenerate) != tt.degenerate || len(r.NonManifold) != tt.nonManifold {
+				t.Errorf("report %+v, want %d intersections, %d degenerate, %d non-manifold",
+					r, tt.intersections, tt.degenerate, tt.nonManifold)
+			}
+			if r.OK() != (tt.intersections+tt.degenerate+tt.nonManifold == 0) {
+				t.Errorf("OK() = %v", r.OK())
+			}
+		})
+	}
+}
+
+func TestTrianglesRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	points := make([][3]Float, 60)
+	for i := range points {
+		points[i] = [3]Float{Float(r.Intn(10)), Float(r.Intn(10)), Float(r.Intn(10))}
+	}
+	triangles := make([][3]int, 200)
+	for i := range triangles {
+		triangles[i] = [3]int{r.Intn(len(points)), r.Intn(len(points)), r.Intn(len(points))}
+	}
+	got := Triangles(points, triangles)
+	// compare with all pairs
+	var want []Intersection
+	degenerate := make(map[int]bool)
+	for _, d := range got.Degenerate {
+		degenerate[d] = true
+	}
+	for a, ta := range triangles {
+		for b := a + 1; b < len(triangles); b++ {
+			tb := triangles[b]
+			if degenerate[a] || degenerate[b] {
+				continue
+			}
+			p := [3][3]Float{points[ta[0]], points[ta[1]], points[ta[2]]}
+			q := [3][3]Float{points[tb[0]], points[tb[1]], points[tb[2]]}
+			kind, contact := predicates.TrianglesIntersect(p, q)
+			allowed := map[int]predicates.Contact{0: predicates.NoContact, 1: predicates.PointContact, 2: predicates.SegmentContact}
+			if kind == predicates.Disjoint || kind == predicates.Touching && allowed[shared(ta, tb)] == contact {
+				continue
+			}
+			want = append(want, Intersection{a, b, kind, contact})
+		}
+	}
+	if len(got.Intersections) != len(want) {
+		t.Fatalf("%d intersections, want %d", len(got.Intersections), len(want))
+	}
+	for i := range want {
+		if got.Intersections[i] != want[i] {
+			t.Fatalf("intersection %d is %+v, want %+v", i, got.Intersections[i], want[i])
+		}
+	}
+}
+
+func TestTetrahedra(t *testing.T) {
+	points := [][3]Float{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}, {0, 0, -1}, {1, 1, 0}, {5, 5, 5}}
+	tests := []struct {
+		name        string
+		tets        [][4]int
+		inverted    []int
+		flat        []int
+		nonManifold int
+	}{
+		{name: "good", tets: [][4]int{{0, 2, 1, 3}, {0, 1, 2, 4}}},
+		{name: "inverted", tets: [][4]int{{0, 1, 2, 3}, {0, 1, 2, 4}}, inverted: []int{0}},
+		{name: "flat", tets: [][4]int{{0, 1, 2, 5}, {0, 2, 1, 3}}, flat: []int{0}},
+		{name: "repeated vertex", tets: [][4]int{{0, 2, 1, 1}}, flat: []int{0}},
+		{name: "three on a face", tets: [][4]int{{0, 2, 1, 3}, {0, 1, 2, 4}, {0, 2, 1, 6}}, nonManifold: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Tetrahedra(points, tt.tets)
+			if !equal(r.Inverted, tt.inverted) || !equal(r.Flat, tt.flat) || len(r.NonManifold) != tt.nonManifold {
+				t.Errorf("report %+v, want inverted %v, flat %v, %d non-manifold", r, tt.inverted, tt.flat, tt.nonManifold)
+			}
+		})
+	}
+}
+
+func equal(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
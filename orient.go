@@ -0,0 +1,163 @@
+package predicates
+
+import "math/bits"
+
+// Orient4dFast returns an approximation of the Orient4d determinant,
+// evaluated in floating point without any error control.
+func Orient4dFast(pa, pb, pc, pd, pe [4]Float) Float {
+	det, _ := orientFast([][]Float{pa[:], pb[:], pc[:], pd[:], pe[:]})
+	return det
+}
+
+// Orient4dExact returns the most significant component of the exact
+// Orient4d determinant, which has its sign.
+func Orient4dExact(pa, pb, pc, pd, pe [4]Float) Float {
+	return OrientNdExact([][]Float{pa[:], pb[:], pc[:], pd[:], pe[:]})
+}
+
+// Orient4d is the four-dimensional Orient3d: it returns the determinant of
+// the matrix whose rows are pa-pe, pb-pe, pc-pe and pd-pe. Its sign tells
+// on which side of the hyperplane through the other four points pe lies,
+// and it is zero when the five points lie on a common hyperplane. Lifting
+// reduces the power test of a regular triangulation in 3D to it. The
+// determinant is evaluated in floating point and only recomputed exactly
+// when its error bound does not settle the sign, in which case the result
+// is that of Orient4dExact. As for the other predicates, the products of
+// the coordinates must not overflow.
+func Orient4d(pa, pb, pc, pd, pe [4]Float) Float {
+	return OrientNd([][]Float{pa[:], pb[:], pc[:], pd[:], pe[:]})
+}
+
+// OrientNdFast returns an approximation of the OrientNd determinant,
+// evaluated in floating point without any error control.
+func OrientNdFast(points [][]Float) Float {
+	det, _ := orientFast(points)
+	return det
+}
+
+// OrientNd generalizes Orient2d, Orient3d and Orient4d to d dimensions:
+// points are d+1 points of d coordinates each, and the result is the
+// determinant of the matrix whose rows are the differences between each of
+// the first d points and the last one. Like Orient4d it returns the
+// floating-point value when its error bound settles the sign, and
+// OrientNdExact otherwise. Both evaluate minors over all subsets of
+// columns, so the cost grows as 2^d and the routine is meant for small d.
+// All the OrientNd functions panic if points is not d+1 points of d
+// coordinates each, for some d >= 0.
+func OrientNd(points [][]Float) Float {
+	det, permanent := orientFast(points)
+	d := len(points) - 1
+	errbound := Float(2*d+d*(d-1)/2) * epsilon * (1 + Float(4*d*d)*epsilon) * permanent
+	if det > errbound || -det > errbound {
+		return det
+	}
+	return OrientNdExact(points)
+}
+
+// OrientNdExact returns the most significant component of the exact
+// OrientNd determinant, which has its sign.
+func OrientNdExact(points [][]Float) Float {
+	e := OrientNdExpansion(points)
+	return e[len(e)-1]
+}
+
+// OrientNdExpansion returns the exact OrientNd determinant as a
+// nonoverlapping expansion, least significant component first. It is
+// computed as the determinant of the points with a coordinate 1 appended,
+// which equals the determinant of the differences but has the coordinates
+// themselves as entries, so every product of an entry and a minor is exact
+// with ScaleExpansionZeroElim.
+func OrientNdExpansion(points [][]Float) []Float {
+	checkOrientNd(points)
+	n := len(points)
+	entry := func(i, j int) Float {
+		if j == n-1 {
+			return 1
+		}
+		return points[i][j]
+	}
+	// minors[s] is the minor of the first k rows and the columns in s
+	minors := make([][]Float, 1<<uint(n))
+	minors[0] = []Float{1}
+	for k := 1; k <= n; k++ {
+		next := make([][]Float, 1<<uint(n))
+		for s := range next {
+			if bits.OnesCount(uint(s)) != k {
+				continue
+			}
+			var sum []Float
+			pos := 0
+			for j := 0; j < n; j++ {
+				if s&(1<<uint(j)) == 0 {
+					continue
+				}
+				m := minors[s&^(1<<uint(j))]
+				if b := entry(k-1, j); b != 0 && len(m) > 0 {
+					t := make([]Float, 2*len(m))
+					t = t[:ScaleExpansionZeroElim(len(m), &m[0], b, &t[0])]
+					if (k-1+pos)%2 == 1 {
+						negate(t)
+					}
+					sum = sumExpansions(sum, t, nil)
+				}
+				pos++
+			}
+			next[s] = sum
+		}
+		minors = next
+	}
+	if e := minors[1<<uint(n)-1]; len(e) > 0 {
+		return e
+	}
+	return []Float{0}
+}
+
+// orientFast returns the OrientNd determinant in floating point, and its
+// permanent, the same sum with the magnitudes of all the products, which
+// bounds its error.
+func orientFast(points [][]Float) (det, permanent Float) {
+	checkOrientNd(points)
+	d := len(points) - 1
+	last := points[d]
+	minors := make([]Float, 1<<uint(d))
+	perms := make([]Float, 1<<uint(d))
+	minors[0], perms[0] = 1, 1
+	for k := 1; k <= d; k++ {
+		next := make([]Float, 1<<uint(d))
+		nextPerms := make([]Float, 1<<uint(d))
+		for s := range next {
+			if bits.OnesCount(uint(s)) != k {
+				continue
+			}
+			pos := 0
+			for j := 0; j < d; j++ {
+				if s&(1<<uint(j)) == 0 {
+					continue
+				}
+				b := points[k-1][j] - last[j]
+				t := b * minors[s&^(1<<uint(j))]
+				if (k-1+pos)%2 == 1 {
+					t = -t
+				}
+				next[s] += t
+				nextPerms[s] += abs(b) * perms[s&^(1<<uint(j))]
+				pos++
+			}
+		}
+		minors, perms = next, nextPerms
+	}
+	return minors[1<<uint(d)-1], perms[1<<uint(d)-1]
+}
+
+// checkOrientNd panics if points is not the input of a determinant of
+// OrientNd, d+1 points of d coordinates each.
+func checkOrientNd(points [][]Float) {
+	if len(points) == 0 {
+		panic("predicates: OrientNd of no points")
+	}
+	for _, p := range points {
+		if len(p) != len(points)-1 {
+			panic("predicates: OrientNd needs d+1 points of d coordinates")
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package predicates
+
+import (
+	"math/big"
+	"testing"
+)
+
+// ratDet returns the determinant of m with rationals, by cofactor
+// expansion along the first row.
+func ratDet(m [][]*big.Rat) *big.Rat {
+	if len(m) == 0 {
+		return big.NewRat(1, 1)
+	}
+	det := new(big.Rat)
+	for j := range m[0] {
+		var minor [][]*big.Rat
+		for _, row := range m[1:] {
+			r := append(append([]*big.Rat(nil), row[:j]...), row[j+1:]...)
+			minor = append(minor, r)
+		}
+		t := new(big.Rat).Mul(m[0][j], ratDet(minor))
+		if j%2 == 1 {
+			t.Neg(t)
+		}
+		det.Add(det, t)
+	}
+	return det
+}
+
+func ratOrient(points [][]Float) int {
+	d := len(points) - 1
+	m := make([][]*big.Rat, d)
+	for i := range m {
+		m[i] = make([]*big.Rat, d)
+		for k := range m[i] {
+			m[i][k] = new(big.Rat).Sub(new(big.Rat).SetFloat64(float64(points[i][k])), new(big.Rat).SetFloat64(float64(points[d][k])))
+		}
+	}
+	return ratDet(m).Sign()
+}
+
+// smallRand is narrowRealRand scaled down by an exact power of two, so
+// that products of five coordinates stay within the range of Float.
+func smallRand() Float {
+	return narrowRealRand() / (1 << 20)
+}
+
+func sign(x Float) int {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return 0
+}
+
+func TestOrientNdLowDimensions(t *testing.T) {
+	for i := 0; i < 5000; i++ {
+		a := [3]Float{narrowRealRand(), narrowRealRand(), narrowRealRand()}
+		b := [3]Float{narrowRealRand(), narrowRealRand(), narrowRealRand()}
+		c := [3]Float{narrowRealRand(), narrowRealRand(), narrowRealRand()}
+		d := [3]Float{narrowRealRand(), narrowRealRand(), narrowRealRand()}
+		if i%2 == 0 {
+			// nearly collinear and nearly coplanar
+			c = [3]Float{(a[0] + b[0]) / 2, (a[1] + b[1]) / 2, (a[2] + b[2]) / 2}
+			d = [3]Float{a[0] + b[0] - c[0], a[1] + c[1] - b[1], a[2] + c[2] - b[2]}
+		}
+		got := OrientNd([][]Float{a[:2], b[:2], c[:2]})
+		if want := Orient2dExact([2]Float{a[0], a[1]}, [2]Float{b[0], b[1]}, [2]Float{c[0], c[1]}); !isSamePred(got, want) {
+			t.Errorf("OrientNd(%v, %v, %v) = %v, want sign of %v", a[:2], b[:2], c[:2], got, want)
+		}
+		got = OrientNd([][]Float{a[:], b[:], c[:], d[:]})
+		if want := Orient3dExact(a, b, c, d); !isSamePred(got, want) {
+			t.Errorf("OrientNd(%v, %v, %v, %v) = %v, want sign of %v", a, b, c, d, got, want)
+		}
+	}
+}
+
+func TestOrient4d(t *testing.T) {
+	for i := 0; i < 3000; i++ {
+		var p [5][4]Float
+		for j := range p {
+			for k := range p[j] {
+				p[j][k] = smallRand()
+			}
+		}
+		if i%2 == 0 {
+			// pe nearly on the hyperplane through the others
+			for k := range p[4] {
+				p[4][k] = p[0][k] + (p[1][k] - p[2][k]) + (p[3][k]-p[0][k])/2
+			}
+		}
+		points := [][]Float{p[0][:], p[1][:], p[2][:], p[3][:], p[4][:]}
+		want := ratOrient(points)
+		if got := Orient4d(p[0], p[1], p[2], p[3], p[4]); sign(got) != want {
+			t.Errorf("Orient4d(%v) = %v, want sign %d", p, got, want)
+		}
+		if got := Orient4dExact(p[0], p[1], p[2], p[3], p[4]); sign(got) != want {
+			t.Errorf("Orient4dExact(%v) = %v, want sign %d", p, got, want)
+		}
+	}
+}
+
+func TestOrient4dLifted(t *testing.T) {
+	// on the paraboloid of lifted points Orient4d is the insphere test
+	lift := func(p [3]Float) [4]Float {
+		return [4]Float{p[0], p[1], p[2], p[0]*p[0] + p[1]*p[1] + p[2]*p[2]}
+	}
+	for i := 0; i < 3000; i++ {
+		var p [5][3]Float
+		for j := range p {
+			for k := range p[j] {
+				p[j][k] = Float(random()%7 - 3)
+			}
+		}
+		want := Insphere(p[0], p[1], p[2], p[3], p[4])
+		got := Orient4d(lift(p[0]), lift(p[1]), lift(p[2]), lift(p[3]), lift(p[4]))
+		if !isSamePred(got, want) {
+			t.Errorf("Orient4d(lifted %v) = %v, want sign of %v", p, got, want)
+		}
+	}
+}
+
+func TestOrientNd(t *testing.T) {
+	// the simplex spanned by the unit vectors from the origin, in 5d
+	const d = 5
+	points := make([][]Float, d+1)
+	for i := range points {
+		points[i] = make([]Float, d)
+	}
+	for i := 0; i < d; i++ {
+		points[i][i] = 1
+	}
+	if got := OrientNd(points); got != 1 {
+		t.Errorf("OrientNd(unit simplex) = %v, want 1", got)
+	}
+	points[0], points[1] = points[1], points[0]
+	if got := OrientNd(points); got != -1 {
+		t.Errorf("OrientNd(swapped) = %v, want -1", got)
+	}
+	// a repeated point
+	points[0] = points[1]
+	if got := OrientNd(points); got != 0 {
+		t.Errorf("OrientNd(repeated) = %v, want 0", got)
+	}
+	for i := 0; i < 500; i++ {
+		for j := range points {
+			for k := range points[j] {
+				points[j][k] = smallRand()
+			}
+		}
+		if i%2 == 0 {
+			for k := range points[d] {
+				points[d][k] = points[0][k] + (points[1][k]-points[2][k])*0.5
+			}
+		}
+		if got, want := OrientNd(points), ratOrient(points); sign(got) != want {
+			t.Errorf("OrientNd(%v) = %v, want sign %d", points, got, want)
+		}
+	}
+}
+
+func TestOrientNdShape(t *testing.T) {
+	for _, points := range [][][]Float{
+		nil,
+		{{0, 0}, {1, 0}},
+		{{0, 0}, {1, 0}, {0, 1}, {1, 1}},
+		{{0, 0}, {1, 0}, {0, 1, 2}},
+	} {
+		for name, f := range map[string]func([][]Float) Float{
+			"OrientNd":      OrientNd,
+			"OrientNdFast":  OrientNdFast,
+			"OrientNdExact": OrientNdExact,
+		} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("%s(%v) did not panic", name, points)
+					}
+				}()
+				f(points)
+			}()
+		}
+	}
+	// the determinant of no rows
+	if got := OrientNd([][]Float{{}}); got != 1 {
+		t.Errorf("OrientNd([[]]) = %v, want 1", got)
+	}
+}
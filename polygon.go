@@ -0,0 +1,243 @@
+package predicates
+
+// A polygon ring is a slice of vertices with an implied closing edge from the
+// last vertex back to the first. Repeating the first vertex at the end is
+// allowed, the resulting zero length edge is ignored.
+
+// FillRule decides which points are inside a polygon from their winding
+// number.
+type FillRule int
+
+const (
+	// NonZero treats points with a non-zero total winding number as inside.
+	// Holes must be oriented opposite to the ring that contains them.
+	NonZero FillRule = iota
+	// EvenOdd treats points with an odd total winding number as inside.
+	// Ring orientation does not matter.
+	EvenOdd
+)
+
+// Inside reports whether the winding number w is inside under the rule.
+func (r FillRule) Inside(w int) bool {
+	if r == EvenOdd {
+		return w&1 != 0
+	}
+	return w != 0
+}
+
+// WindingNumber returns the number of times the ring poly winds around p,
+// positive for counterclockwise turns. Crossings are decided with Orient2d
+// and exact comparisons of the y coordinates (edges are half-open in y), so
+// the result is exact for self-intersecting rings and does not depend on
+// which vertex the ring starts at.
+//
+// The winding number is not defined for points on the ring itself; use
+// PointInPolygon to detect them.
+func WindingNumber(poly [][2]Float, p [2]Float) int {
+	wn := 0
+	n := len(poly)
+	for i := 0; i < n; i++ {
+		a, b := poly[i], poly[(i+1)%n]
+		if a[1] <= p[1] {
+			if b[1] > p[1] && Orient2d(a, b, p) > 0 {
+				wn++
+			}
+		} else {
+			if b[1] <= p[1] && Orient2d(a, b, p) < 0 {
+				wn--
+			}
+		}
+	}
+	return wn
+}
+
+// ringBoundary locates p on the boundary of the ring poly. It returns
+// Outside when p is not on the ring.
+func ringBoundary(poly [][2]Float, p [2]Float) Location {
+	n := len(poly)
+	for i := 0; i < n; i++ {
+		if poly[i] == p {
+			return OnVertex
+		}
+	}
+	for i := 0; i < n; i++ {
+		a, b := poly[i], poly[(i+1)%n]
+		if a == b {
+			continue
+		}
+		// cheap bounding box rejection before the orientation test
+		if p[0] < a[0] && p[0] < b[0] || p[0] > a[0] && p[0] > b[0] ||
+			p[1] < a[1] && p[1] < b[1] || p[1] > a[1] && p[1] > b[1] {
+			continue
+		}
+		if onSegment(a, b, p) {
+			return OnEdge
+		}
+	}
+	return Outside
+}
+
+// PointInPolygon locates p relative to the polygon bounded by rings, which
+// may be self-intersecting and may contain holes. Points on any ring are
+// reported as OnVertex or OnEdge; other points are Inside or Outside
+// according to rule applied to the sum of the winding numbers of all rings.
+func PointInPolygon(rings [][][2]Float, p [2]Float, rule FillRule) Location {
+	onEdge := false
+	for _, ring := range rings {
+		switch ringBoundary(ring, p) {
+		case OnVertex:
+			return OnVertex
+		case OnEdge:
+			onEdge = true
+		}
+	}
+	if onEdge {
+		return OnEdge
+	}
+
+	wn := 0
+	for _, ring := range rings {
+		wn += WindingNumber(ring, p)
+	}
+	if rule.Inside(wn) {
+		return Inside
+	}
+	return Outside
+}
+
+// compactRing returns poly without repeated consecutive vertices, including
+// a closing vertex equal to the first one.
+func compactRing(poly [][2]Float) [][2]Float {
+	r := make([][2]Float, 0, len(poly))
+	for _, v := range poly {
+		if len(r) == 0 || r[len(r)-1] != v {
+			r = append(r, v)
+		}
+	}
+	for len(r) > 1 && r[len(r)-1] == r[0] {
+		r = r[:len(r)-1]
+	}
+	return r
+}
+
+// PolygonArea2 returns twice the signed area of the ring poly as a
+// nonoverlapping expansion, least significant component first. It is
+// accumulated exactly from the shoelace terms x[i]*y[i+1] - x[i+1]*y[i]
+// with FastExpansionSumZeroElim, so the only rounding error is the one made
+// when the caller finally collapses it, for example with Estimate.
+func PolygonArea2(poly [][2]Float) []Float {
+	n := len(poly)
+	sum := make([]Float, 0, 4*n)
+	tmp := make([]Float, 0, 4*n)
+	for i := 0; i < n; i++ {
+		a, b := poly[i], poly[(i+1)%n]
+		t := crossProduct(a[0], a[1], b[0], b[1])
+		tmp = sumExpansions(sum, t[:], tmp)
+		sum, tmp = tmp, sum
+	}
+	if len(sum) == 0 {
+		sum = append(sum, 0)
+	}
+	return sum
+}
+
+// PolygonOrientation returns a positive value if the ring poly is
+// counterclockwise, a negative value if it is clockwise, and zero if its
+// signed area vanishes. The signed area is accumulated exactly with
+// expansion arithmetic, so the sign is correct even for slivers where the
+// naive shoelace sum cancels out. As for Orient2dExact, the result is the
+// most significant component of the expansion, which has its sign and is
+// also an approximation of twice the signed area. For self-intersecting
+// rings it is the sign of the integral of the winding number.
+func PolygonOrientation(poly [][2]Float) Float {
+	area := PolygonArea2(poly)
+	return area[len(area)-1]
+}
+
+// lexDir returns the direction of b relative to a in lexicographic order.
+func lexDir(a, b [2]Float) int {
+	if lessXY(a, b) {
+		return 1
+	}
+	if lessXY(b, a) {
+		return -1
+	}
+	return 0
+}
+
+// IsConvex reports whether the ring poly is the boundary of a convex region
+// with positive area, traversed once in either direction. Collinear
+// vertices along an edge are allowed, repeated vertices are ignored.
+func IsConvex(poly [][2]Float) bool {
+	poly = compactRing(poly)
+	n := len(poly)
+	if n < 3 {
+		return false
+	}
+	turn := 0
+	changes := 0
+	for i := 0; i < n; i++ {
+		a, b, c := poly[i], poly[(i+1)%n], poly[(i+2)%n]
+		o := Orient2d(a, b, c)
+		d1, d2 := lexDir(a, b), lexDir(b, c)
+		switch {
+		case o > 0:
+			if turn < 0 {
+				return false
+			}
+			turn = 1
+		case o < 0:
+			if turn > 0 {
+				return false
+			}
+			turn = -1
+		default:
+			if d1 != d2 {
+				// the ring folds back on itself
+				return false
+			}
+		}
+		if d1 != d2 {
+			changes++
+		}
+	}
+	// a convex ring goes up and down the lexicographic order exactly once,
+	// rings turning the same way at every vertex but winding more than once
+	// change direction more often
+	return turn != 0 && changes == 2
+}
+
+// IsSimple reports whether the ring poly has no self-intersections: edges
+// that are not adjacent must be disjoint, and adjacent edges may only share
+// their common vertex. Repeated consecutive vertices are ignored. Rings with
+// fewer than three distinct vertices are not simple.
+func IsSimple(poly [][2]Float) bool {
+	poly = compactRing(poly)
+	n := len(poly)
+	if n < 3 {
+		return false
+	}
+	for i := 0; i < n; i++ {
+		a1, a2 := poly[i], poly[(i+1)%n]
+		for j := i + 1; j < n; j++ {
+			b1, b2 := poly[j], poly[(j+1)%n]
+			if a1[0] < b1[0] && a1[0] < b2[0] && a2[0] < b1[0] && a2[0] < b2[0] ||
+				a1[0] > b1[0] && a1[0] > b2[0] && a2[0] > b1[0] && a2[0] > b2[0] ||
+				a1[1] < b1[1] && a1[1] < b2[1] && a2[1] < b1[1] && a2[1] < b2[1] ||
+				a1[1] > b1[1] && a1[1] > b2[1] && a2[1] > b1[1] && a2[1] > b2[1] {
+				continue
+			}
+			k := SegmentsIntersect(a1, a2, b1, b2)
+			if j == i+1 || i == 0 && j == n-1 {
+				// adjacent edges meet at their shared vertex, anything
+				// more is a fold back along the same line
+				if k != Touching {
+					return false
+				}
+			} else if k != Disjoint {
+				return false
+			}
+		}
+	}
+	return true
+}
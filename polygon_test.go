@@ -0,0 +1,185 @@
+package predicates
+
+import (
+	"math"
+	"testing"
+)
+
+// rotate returns ring starting at vertex k.
+func rotate(ring [][2]Float, k int) [][2]Float {
+	return append(append([][2]Float{}, ring[k:]...), ring[:k]...)
+}
+
+func reverse(ring [][2]Float) [][2]Float {
+	r := make([][2]Float, len(ring))
+	for i, v := range ring {
+		r[len(ring)-1-i] = v
+	}
+	return r
+}
+
+func TestWindingNumber(t *testing.T) {
+	square := [][2]Float{{0, 0}, {4, 0}, {4, 4}, {0, 4}}
+	// a pentagram, its center is wound twice
+	star := [][2]Float{{0, 10}, {-6, -8}, {9, 3}, {-9, 3}, {6, -8}}
+	// a ring going twice around the square
+	twice := append(append([][2]Float{}, square...), square...)
+	tests := []struct {
+		name string
+		poly [][2]Float
+		p    [2]Float
+		want int
+	}{
+		{name: "square inside", poly: square, p: [2]Float{1, 1}, want: 1},
+		{name: "square inside at vertex height", poly: square, p: [2]Float{2, 0.5}, want: 1},
+		{name: "square outside", poly: square, p: [2]Float{5, 1}, want: 0},
+		{name: "square outside level with edge", poly: square, p: [2]Float{5, 4}, want: 0},
+		{name: "square outside level with vertex", poly: square, p: [2]Float{-1, 0}, want: 0},
+		{name: "star center", poly: star, p: [2]Float{0, 0}, want: 2},
+		{name: "star point", poly: star, p: [2]Float{0, 7}, want: 1},
+		{name: "star outside", poly: star, p: [2]Float{8, 8}, want: 0},
+		{name: "twice", poly: twice, p: [2]Float{1, 1}, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k := range tt.poly {
+				ring := rotate(tt.poly, k)
+				if got := WindingNumber(ring, tt.p); got != tt.want {
+					t.Errorf("WindingNumber() start=%d = %v, want %v", k, got, tt.want)
+				}
+				if got := WindingNumber(reverse(ring), tt.p); got != -tt.want {
+					t.Errorf("WindingNumber() reversed start=%d = %v, want %v", k, got, -tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestPointInPolygon(t *testing.T) {
+	outer := [][2]Float{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}
+	hole := [][2]Float{{3, 3}, {3, 7}, {7, 7}, {7, 3}}
+	tests := []struct {
+		name  string
+		rings [][][2]Float
+		p     [2]Float
+		rule  FillRule
+		want  Location
+	}{
+		{name: "inside", rings: [][][2]Float{outer, hole}, p: [2]Float{1, 1}, want: Inside},
+		{name: "in hole", rings: [][][2]Float{outer, hole}, p: [2]Float{5, 5}, want: Outside},
+		{name: "in hole evenodd", rings: [][][2]Float{outer, reverse(hole)}, p: [2]Float{5, 5}, rule: EvenOdd, want: Outside},
+		{name: "in same-oriented hole nonzero", rings: [][][2]Float{outer, reverse(hole)}, p: [2]Float{5, 5}, want: Inside},
+		{name: "outside", rings: [][][2]Float{outer, hole}, p: [2]Float{11, 5}, want: Outside},
+		{name: "on outer edge", rings: [][][2]Float{outer, hole}, p: [2]Float{10, 5}, want: OnEdge},
+		{name: "on closing edge", rings: [][][2]Float{outer, hole}, p: [2]Float{0, 5}, want: OnEdge},
+		{name: "on hole edge", rings: [][][2]Float{outer, hole}, p: [2]Float{5, 7}, want: OnEdge},
+		{name: "on hole vertex", rings: [][][2]Float{outer, hole}, p: [2]Float{7, 3}, want: OnVertex},
+		{name: "on outer vertex", rings: [][][2]Float{outer, hole}, p: [2]Float{10, 10}, want: OnVertex},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PointInPolygon(tt.rings, tt.p, tt.rule); got != tt.want {
+				t.Errorf("PointInPolygon() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindingNumberRand(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		ring := make([][2]Float, 3+i%7)
+		for j := range ring {
+			ring[j] = [2]Float{narrowRealRand(), narrowRealRand()}
+		}
+		p := [2]Float{narrowRealRand(), narrowRealRand()}
+		want := WindingNumber(ring, p)
+		for k := 1; k < len(ring); k++ {
+			if got := WindingNumber(rotate(ring, k), p); got != want {
+				t.Fatalf("WindingNumber() start=%d = %v, want %v, ring=%v, p=%v", k, got, want, ring, p)
+			}
+		}
+	}
+}
+
+func TestPolygonOrientation(t *testing.T) {
+	square := [][2]Float{{0, 0}, {4, 0}, {4, 4}, {0, 4}}
+	if got := PolygonOrientation(square); got != 32 {
+		t.Errorf("PolygonOrientation() = %v, want 32", got)
+	}
+	if got := PolygonOrientation(reverse(square)); got != -32 {
+		t.Errorf("PolygonOrientation() = %v, want -32", got)
+	}
+	// a bow tie has zero signed area
+	if got := PolygonOrientation([][2]Float{{0, 0}, {2, 2}, {2, 0}, {0, 2}}); got != 0 {
+		t.Errorf("PolygonOrientation() = %v, want 0", got)
+	}
+}
+
+func TestPolygonOrientationSliver(t *testing.T) {
+	// a sliver far from the origin, every shoelace term is about 1e9 while
+	// the area is a fraction of a unit
+	pa := [2]Float{30000, 30000}
+	pb := [2]Float{30002, 30003}
+	y := Float(75000 - 1)
+	for i := 0; i < 1000; i++ {
+		y = Float(math.Nextafter32(float32(y), 1e10))
+		pc := [2]Float{60000, y}
+		want := Orient2dExact(pa, pb, pc)
+		got := PolygonOrientation([][2]Float{pa, pb, pc})
+		if !isSamePred(got, want) {
+			t.Fatalf("PolygonOrientation() = %v, want sign %v, pc=%v", got, want, pc)
+		}
+		got = PolygonOrientation([][2]Float{pa, pb, pc, pa, pc, pb, pa, pb, pc})
+		if !isSamePred(got, want) {
+			t.Fatalf("PolygonOrientation() = %v, want sign %v, pc=%v", got, want, pc)
+		}
+	}
+}
+
+func TestIsConvex(t *testing.T) {
+	tests := []struct {
+		name string
+		poly [][2]Float
+		want bool
+	}{
+		{name: "square", poly: [][2]Float{{0, 0}, {4, 0}, {4, 4}, {0, 4}}, want: true},
+		{name: "square closed", poly: [][2]Float{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}, want: true},
+		{name: "square clockwise", poly: [][2]Float{{0, 0}, {0, 4}, {4, 4}, {4, 0}}, want: true},
+		{name: "collinear vertex", poly: [][2]Float{{0, 0}, {2, 0}, {4, 0}, {4, 4}, {0, 4}}, want: true},
+		{name: "notch", poly: [][2]Float{{0, 0}, {4, 0}, {2, 1}, {4, 4}, {0, 4}}, want: false},
+		{name: "star", poly: [][2]Float{{0, 10}, {-6, -8}, {9, 3}, {-9, 3}, {6, -8}}, want: false},
+		{name: "spike", poly: [][2]Float{{0, 0}, {4, 0}, {6, 0}, {4, 0}, {4, 4}}, want: false},
+		{name: "segment", poly: [][2]Float{{0, 0}, {4, 0}, {2, 0}}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsConvex(tt.poly); got != tt.want {
+				t.Errorf("IsConvex() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSimple(t *testing.T) {
+	tests := []struct {
+		name string
+		poly [][2]Float
+		want bool
+	}{
+		{name: "square", poly: [][2]Float{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}, want: true},
+		{name: "notch", poly: [][2]Float{{0, 0}, {4, 0}, {2, 1}, {4, 4}, {0, 4}}, want: true},
+		{name: "bow tie", poly: [][2]Float{{0, 0}, {2, 2}, {2, 0}, {0, 2}}, want: false},
+		{name: "star", poly: [][2]Float{{0, 10}, {-6, -8}, {9, 3}, {-9, 3}, {6, -8}}, want: false},
+		{name: "touching vertex", poly: [][2]Float{{0, 0}, {4, 0}, {2, 2}, {4, 4}, {0, 4}, {2, 2}}, want: false},
+		{name: "vertex on edge", poly: [][2]Float{{0, 0}, {4, 0}, {4, 4}, {2, 0}, {0, 4}}, want: false},
+		{name: "fold back", poly: [][2]Float{{0, 0}, {4, 0}, {2, 0}, {2, 4}}, want: false},
+		{name: "degenerate triangle", poly: [][2]Float{{0, 0}, {1, 1}, {2, 2}}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsSimple(tt.poly); got != tt.want {
+				t.Errorf("IsSimple() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
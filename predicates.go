@@ -34,6 +34,7 @@ var (
 	o3derrboundA, o3derrboundB, o3derrboundC Float
 	iccerrboundA, iccerrboundB, iccerrboundC Float
 	isperrboundA, isperrboundB, isperrboundC Float
+	dsterrboundA, doterrboundA               Float
 )
 
 func doubleToString(number float64) (s string) {
@@ -258,6 +259,8 @@ func init() {
 	isperrboundA = (16.0 + 224.0*epsilon) * epsilon
 	isperrboundB = (5.0 + 72.0*epsilon) * epsilon
 	isperrboundC = (71.0 + 1408.0*epsilon) * epsilon * epsilon
+	dsterrboundA = (8.0 + 64.0*epsilon) * epsilon
+	doterrboundA = (6.0 + 48.0*epsilon) * epsilon
 
 	ensureOrient2dWorks()
 }
@@ -1022,12 +1025,22 @@ func Orient2dFast(pa [2]Float, pb [2]Float, pc [2]Float) Float {
 }
 
 func Orient2dExact(pa [2]Float, pb [2]Float, pc [2]Float) Float {
+	var w [12]Float
+	var wlength int
+
+	wlength = orient2dExact(pa, pb, pc, &w)
+
+	return w[wlength-1]
+}
+
+// orient2dExact writes the exact value of the orient2d determinant to w as
+// an expansion and returns its length.
+func orient2dExact(pa [2]Float, pb [2]Float, pc [2]Float, w *[12]Float) int {
 	var axby1, axcy1, bxcy1, bxay1, cxay1, cxby1 Float
 	var axby0, axcy0, bxcy0, bxay0, cxay0, cxby0 Float
 	var aterms, bterms, cterms [4]Float
 	var aterms3, bterms3, cterms3 Float
 	var v [8]Float
-	var w [12]Float
 	var vlength, wlength int
 
 	var bvirt Float
@@ -1201,7 +1214,7 @@ func Orient2dExact(pa [2]Float, pb [2]Float, pc [2]Float) Float {
 	vlength = FastExpansionSumZeroElim(4, &aterms[0], 4, &bterms[0], &v[0])
 	wlength = FastExpansionSumZeroElim(vlength, &v[0], 4, &cterms[0], &w[0])
 
-	return w[wlength-1]
+	return wlength
 }
 
 func Orient2dSlow(pa [2]Float, pb [2]Float, pc [2]Float) Float {
@@ -1691,7 +1704,7 @@ func Orient2dAdapt(pa [2]Float, pb [2]Float, pc [2]Float, detsum Float) Float {
 	around = _j - avirt
 	u[2] = around + bround
 	u[3] = u3
-	C1length = FastExpansionSumZeroElim(4, &B[0], 4, &B[0], &C1[0])
+	C1length = FastExpansionSumZeroElim(4, &B[0], 4, &u[0], &C1[0])
 
 	s1 = (Float)(acx * bcytail)
 	c = (Float)(splitter * acx)
@@ -1881,6 +1894,17 @@ func Orient3dFast(pa [3]Float, pb [3]Float, pc [3]Float, pd [3]Float) Float {
 }
 
 func Orient3dExact(pa [3]Float, pb [3]Float, pc [3]Float, pd [3]Float) Float {
+	var deter [96]Float
+	var deterlen int
+
+	deterlen = orient3dExact(pa, pb, pc, pd, &deter)
+
+	return deter[deterlen-1]
+}
+
+// orient3dExact writes the exact value of the orient3d determinant to deter
+// as an expansion and returns its length.
+func orient3dExact(pa [3]Float, pb [3]Float, pc [3]Float, pd [3]Float, deter *[96]Float) int {
 	var axby1, bxcy1, cxdy1, dxay1, axcy1, bxdy1 Float
 	var bxay1, cxby1, dxcy1, axdy1, cxay1, dxby1 Float
 	var axby0, bxcy0, cxdy0, dxay0, axcy0, bxdy0 Float
@@ -1894,7 +1918,6 @@ func Orient3dExact(pa [3]Float, pb [3]Float, pc [3]Float, pd [3]Float) Float {
 	var alen, blen, clen, dlen int
 	var abdet, cddet [48]Float
 	var ablen, cdlen int
-	var deter [96]Float
 	var deterlen int
 	var i int
 
@@ -2235,7 +2258,7 @@ func Orient3dExact(pa [3]Float, pb [3]Float, pc [3]Float, pd [3]Float) Float {
 	cdlen = FastExpansionSumZeroElim(clen, &cdet[0], dlen, &ddet[0], &cddet[0])
 	deterlen = FastExpansionSumZeroElim(ablen, &abdet[0], cdlen, &cddet[0], &deter[0])
 
-	return deter[deterlen-1]
+	return deterlen
 }
 
 func Orient3dSlow(pa, pb, pc, pd [3]Float) Float {
@@ -10748,18 +10771,51 @@ func Incircle2pFast(pa, pb, pc [2]Float) Float {
 	return -acx*bcx - acy*bcy
 }
 
+// Incircle2p returns a positive value if pc lies inside the circle with
+// diameter pa pb, a negative value if it lies outside, and zero if it is on
+// the circle. Like Orient2d it returns the floating-point value when its
+// error bound settles the sign, and otherwise an approximation of the exact
+// value: the most significant component of the expansion of the dot product
+// that DotSign2d evaluates, negated. Unlike Incircle2pFast, whose rounding
+// can give the wrong sign or a spurious zero, the sign is always exact.
 func Incircle2p(pa, pb, pc [2]Float) Float {
-	// return Incircle(pa, pa, pb, pc) // it doesn't works!
-	det := Incircle2pFast(pa, pb, pc)
-
-	// 这个算法也是浮点运算, 那么它是否和Orient2d一样, 有精度问题呢? 我不知道.
-	// 这里暂且测试一下它的结果和双精度运算是否一致, 等遇到不一致的情况, 再研究如何改进.
-	// acx := float64(pa[0]) - float64(pc[0])
-	// bcx := float64(pb[0]) - float64(pc[0])
-	// acy := float64(pa[1]) - float64(pc[1])
-	// bcy := float64(pb[1]) - float64(pc[1])
-	// det1 := -acx*bcx - acy*bcy
-	// debug.Assert(det1 == 0 && det == 0 || det1 < 0 && det < 0 || det1 > 0 && det > 0)
-
-	return det
+	det, errbound := dotFast(pa[:], pb[:], pc[:])
+	if det > errbound || -det > errbound {
+		return -det
+	}
+	e := dotExact(pa[:], pb[:], pc[:])
+	return -e[len(e)-1]
+}
+
+// Insphere3p is Incircle2p in three dimensions: it returns a positive value
+// if pd lies inside the smallest sphere through pa, pb and pc, the one
+// centered in their plane, a negative value if it lies outside, and zero if
+// it is on that sphere or if pa, pb and pc are collinear. With the points
+// relative to pa and n = b×c, the center is k / 2|n|^2 for
+// k = |b|^2 c×n + |c|^2 n×b, and the result is the most significant
+// component of the exact p·k - |n|^2 |p|^2. That has degree six, so the
+// differences are scaled as in CircumradiusSq2d, within the same limits.
+func Insphere3p(pa, pb, pc, pd [3]Float) Float {
+	var b, c, p [3][]Float
+	var all [][]Float
+	for k := 0; k < 3; k++ {
+		b[k], c[k], p[k] = difference(pb[k], pa[k]), difference(pc[k], pa[k]), difference(pd[k], pa[k])
+		all = append(all, b[k], c[k], p[k])
+	}
+	s := normalizingExp(16, all...)
+	for k := 0; k < 3; k++ {
+		b[k], c[k], p[k] = ldexp(b[k], s), ldexp(c[k], s), ldexp(p[k], s)
+	}
+	n := cross(b, c)
+	cn, nb := cross(c, n), cross(n, b)
+	b2, c2 := dot(b, b), dot(c, c)
+	var center [3][]Float
+	for k := 0; k < 3; k++ {
+		center[k] = sumExpansions(multiply(b2, cn[k]), multiply(c2, nb[k]), nil)
+	}
+	e := sumExpansions(dot(p, center), negate(multiply(dot(n, n), dot(p, p))), nil)
+	if len(e) == 0 {
+		return 0
+	}
+	return e[len(e)-1]
 }
@@ -1,6 +1,7 @@
 package predicates
 
 import (
+	"math/big"
 	"testing"
 	"unsafe"
 )
@@ -57,6 +58,22 @@ func TestOrient2dRand(t *testing.T) {
 	}
 }
 
+func TestOrient2dNearlyCollinear(t *testing.T) {
+	// points rounded onto a line, so the differences are inexact and the
+	// last stage of Orient2dAdapt decides
+	for i := 0; i < 100000; i++ {
+		pa := [2]Float{narrowRealRand(), narrowRealRand()}
+		pb := [2]Float{narrowRealRand(), narrowRealRand()}
+		pc := [2]Float{pa[0] + (pb[0]-pa[0])*1.5, pa[1] + (pb[1]-pa[1])*1.5}
+
+		te := Orient2dExact(pa, pb, pc)
+		tn := Orient2d(pa, pb, pc)
+		if !isSamePred(te, tn) {
+			t.Fatalf("Orient2dExact()=%v, Orient2d()=%v, pa=%v, pb=%v, pc=%v", te, tn, pa, pb, pc)
+		}
+	}
+}
+
 func TestOrientSign(t *testing.T) {
 	// a,b,c 逆时针排列, predicates里的注释, 它应该返回正值
 	pa, pb, pc := [2]Float{0, 0}, [2]Float{1, 0}, [2]Float{0, 1}
@@ -148,6 +165,94 @@ func TestIncircle2p(t *testing.T) {
 	}
 }
 
+func TestIncircle2pNearCircle(t *testing.T) {
+	r := func(x Float) *big.Rat { return new(big.Rat).SetFloat64(float64(x)) }
+	for i := 0; i < 10000; i++ {
+		// pa and pb seen from pc at nearly a right angle, so that the exact
+		// arithmetic decides
+		pc := [2]Float{narrowRealRand(), narrowRealRand()}
+		p, q := narrowRealRand(), narrowRealRand()
+		pa := [2]Float{pc[0] + p, pc[1] + q}
+		pb := [2]Float{pc[0] - q, pc[1] + p}
+		want := new(big.Rat).Mul(new(big.Rat).Sub(r(pa[0]), r(pc[0])), new(big.Rat).Sub(r(pb[0]), r(pc[0])))
+		want.Add(want, new(big.Rat).Mul(new(big.Rat).Sub(r(pa[1]), r(pc[1])), new(big.Rat).Sub(r(pb[1]), r(pc[1]))))
+		if got := Incircle2p(pa, pb, pc); sign(got) != -want.Sign() {
+			t.Errorf("Incircle2p(%v, %v, %v) = %v, want sign %d", pa, pb, pc, got, -want.Sign())
+		}
+	}
+}
+
+func TestInsphere3p(t *testing.T) {
+	r := func(x Float) *big.Rat { return new(big.Rat).SetFloat64(float64(x)) }
+	for i := 0; i < 5000; i++ {
+		var a, b, c, p [3]Float
+		for k := 0; k < 3; k++ {
+			a[k], b[k], c[k], p[k] = narrowRealRand(), narrowRealRand(), narrowRealRand(), narrowRealRand()
+		}
+		switch i % 3 {
+		case 0:
+			// on the sphere
+			p = [3][3]Float{a, b, c}[i%9/3]
+		case 1:
+			// next to c, where the sign is decided by a few bits
+			for k := 0; k < 3; k++ {
+				p[k] = c[k] + (b[k]-a[k])/(1<<20)
+			}
+		}
+		// p·k - |n|^2 |p|^2 of Insphere3p, with big.Rat
+		var u, v, w [3]*big.Rat
+		for k := 0; k < 3; k++ {
+			u[k], v[k], w[k] = new(big.Rat).Sub(r(b[k]), r(a[k])), new(big.Rat).Sub(r(c[k]), r(a[k])), new(big.Rat).Sub(r(p[k]), r(a[k]))
+		}
+		cross := func(x, y [3]*big.Rat) (z [3]*big.Rat) {
+			for k := 0; k < 3; k++ {
+				i, j := (k+1)%3, (k+2)%3
+				z[k] = new(big.Rat).Mul(x[i], y[j])
+				z[k].Sub(z[k], new(big.Rat).Mul(x[j], y[i]))
+			}
+			return z
+		}
+		dot := func(x, y [3]*big.Rat) *big.Rat {
+			s := new(big.Rat)
+			for k := 0; k < 3; k++ {
+				s.Add(s, new(big.Rat).Mul(x[k], y[k]))
+			}
+			return s
+		}
+		n := cross(u, v)
+		cn, nb := cross(v, n), cross(n, u)
+		want := new(big.Rat)
+		for k := 0; k < 3; k++ {
+			x := new(big.Rat).Mul(dot(u, u), cn[k])
+			x.Add(x, new(big.Rat).Mul(dot(v, v), nb[k]))
+			want.Add(want, x.Mul(x, w[k]))
+		}
+		want.Sub(want, new(big.Rat).Mul(dot(n, n), dot(w, w)))
+		if got := Insphere3p(a, b, c, p); sign(got) != want.Sign() {
+			t.Errorf("Insphere3p(%v, %v, %v, %v) = %v, want sign %d", a, b, c, p, got, want.Sign())
+		}
+	}
+	// the unit sphere is the smallest through three points of a great circle
+	a, b, c := [3]Float{1, 0, 0}, [3]Float{0, 1, 0}, [3]Float{-1, 0, 0}
+	for _, tt := range []struct {
+		p    [3]Float
+		want Float
+	}{
+		{[3]Float{0, 0, 1}, 0},
+		{[3]Float{0, 0, 0.5}, 1},
+		{[3]Float{0, 0, -1.5}, -1},
+		{[3]Float{0, -1, 0}, 0},
+		{[3]Float{0, 0, -1}, 0},
+	} {
+		if got := Insphere3p(a, b, c, tt.p); !isSamePred(got, tt.want) {
+			t.Errorf("Insphere3p(%v) = %v, want sign %v", tt.p, got, tt.want)
+		}
+	}
+	if got := Insphere3p([3]Float{0, 0, 0}, [3]Float{1, 1, 1}, [3]Float{2, 2, 2}, [3]Float{1, 0, 0}); got != 0 {
+		t.Errorf("Insphere3p(collinear) = %v, want 0", got)
+	}
+}
+
 func TestIncircle2pRand(t *testing.T) {
 	for i := 0; i < 10000; i++ {
 		pa := [2]Float{narrowRealRand(), narrowRealRand()}
@@ -0,0 +1,113 @@
+package predicates
+
+// IntersectionKind classifies how two closed segments meet.
+type IntersectionKind int
+
+const (
+	// Disjoint segments have no point in common.
+	Disjoint IntersectionKind = iota
+	// Touching segments meet in exactly one point which is an endpoint of at
+	// least one of them (shared endpoints, T-junctions, collinear end-to-end).
+	Touching
+	// Crossing segments meet in exactly one point interior to both.
+	Crossing
+	// Overlapping segments are collinear and share a piece of positive length.
+	Overlapping
+)
+
+func (k IntersectionKind) String() string {
+	switch k {
+	case Disjoint:
+		return "Disjoint"
+	case Touching:
+		return "Touching"
+	case Crossing:
+		return "Crossing"
+	case Overlapping:
+		return "Overlapping"
+	}
+	return "IntersectionKind(?)"
+}
+
+// lessXY orders points lexicographically by x then y. Along any line this is
+// the same as the order of the points on the line, so it is the exact
+// comparison used for collinear points.
+func lessXY(a, b [2]Float) bool {
+	return a[0] < b[0] || a[0] == b[0] && a[1] < b[1]
+}
+
+// onSegment reports whether p lies on the closed segment ab. a and b must be
+// distinct.
+func onSegment(a, b, p [2]Float) bool {
+	if Orient2d(a, b, p) != 0 {
+		return false
+	}
+	if lessXY(b, a) {
+		a, b = b, a
+	}
+	return !lessXY(p, a) && !lessXY(b, p)
+}
+
+// SegmentsIntersect classifies the intersection of the closed segments p1p2
+// and q1q2. Every decision is taken from the signs of Orient2d and from exact
+// coordinate comparisons, so the result never contradicts Orient2d.
+// A segment whose endpoints coincide is treated as a single point.
+func SegmentsIntersect(p1, p2, q1, q2 [2]Float) IntersectionKind {
+	if p1 == p2 || q1 == q2 {
+		switch {
+		case p1 == p2 && q1 == q2:
+			if p1 == q1 {
+				return Touching
+			}
+		case p1 == p2:
+			if onSegment(q1, q2, p1) {
+				return Touching
+			}
+		default:
+			if onSegment(p1, p2, q1) {
+				return Touching
+			}
+		}
+		return Disjoint
+	}
+
+	o1 := Orient2d(p1, p2, q1)
+	o2 := Orient2d(p1, p2, q2)
+	if o1 > 0 && o2 > 0 || o1 < 0 && o2 < 0 {
+		return Disjoint
+	}
+	o3 := Orient2d(q1, q2, p1)
+	o4 := Orient2d(q1, q2, p2)
+	if o3 > 0 && o4 > 0 || o3 < 0 && o4 < 0 {
+		return Disjoint
+	}
+
+	if o1 == 0 && o2 == 0 {
+		// all four points are collinear, compare them along the line
+		if lessXY(p2, p1) {
+			p1, p2 = p2, p1
+		}
+		if lessXY(q2, q1) {
+			q1, q2 = q2, q1
+		}
+		lo, hi := p1, p2
+		if lessXY(lo, q1) {
+			lo = q1
+		}
+		if lessXY(q2, hi) {
+			hi = q2
+		}
+		switch {
+		case lessXY(lo, hi):
+			return Overlapping
+		case lo == hi:
+			return Touching
+		}
+		return Disjoint
+	}
+
+	if o1 == 0 || o2 == 0 || o3 == 0 || o4 == 0 {
+		return Touching
+	}
+	return Crossing
+}
@@ -0,0 +1,106 @@
+package predicates
+
+import "testing"
+
+func TestSegmentsIntersect(t *testing.T) {
+	type args struct {
+		p1, p2, q1, q2 [2]Float
+	}
+	tests := []struct {
+		name string
+		args args
+		want IntersectionKind
+	}{
+		{
+			name: "crossing",
+			args: args{p1: [2]Float{0, 0}, p2: [2]Float{2, 2}, q1: [2]Float{0, 2}, q2: [2]Float{2, 0}},
+			want: Crossing,
+		},
+		{
+			name: "disjoint",
+			args: args{p1: [2]Float{0, 0}, p2: [2]Float{1, 0}, q1: [2]Float{0, 1}, q2: [2]Float{1, 1}},
+			want: Disjoint,
+		},
+		{
+			name: "disjoint on crossing lines",
+			args: args{p1: [2]Float{0, 0}, p2: [2]Float{1, 1}, q1: [2]Float{3, 0}, q2: [2]Float{2, 1}},
+			want: Disjoint,
+		},
+		{
+			name: "shared endpoint",
+			args: args{p1: [2]Float{0, 0}, p2: [2]Float{1, 0}, q1: [2]Float{1, 0}, q2: [2]Float{1, 1}},
+			want: Touching,
+		},
+		{
+			name: "T junction",
+			args: args{p1: [2]Float{0, 0}, p2: [2]Float{2, 0}, q1: [2]Float{1, 0}, q2: [2]Float{1, 1}},
+			want: Touching,
+		},
+		{
+			name: "collinear overlap",
+			args: args{p1: [2]Float{0, 0}, p2: [2]Float{2, 2}, q1: [2]Float{3, 3}, q2: [2]Float{1, 1}},
+			want: Overlapping,
+		},
+		{
+			name: "collinear contained",
+			args: args{p1: [2]Float{0, 0}, p2: [2]Float{0, 4}, q1: [2]Float{0, 1}, q2: [2]Float{0, 2}},
+			want: Overlapping,
+		},
+		{
+			name: "collinear end to end",
+			args: args{p1: [2]Float{0, 0}, p2: [2]Float{1, 1}, q1: [2]Float{2, 2}, q2: [2]Float{1, 1}},
+			want: Touching,
+		},
+		{
+			name: "collinear apart",
+			args: args{p1: [2]Float{0, 0}, p2: [2]Float{1, 1}, q1: [2]Float{2, 2}, q2: [2]Float{3, 3}},
+			want: Disjoint,
+		},
+		{
+			name: "point on segment",
+			args: args{p1: [2]Float{1, 1}, p2: [2]Float{1, 1}, q1: [2]Float{0, 0}, q2: [2]Float{2, 2}},
+			want: Touching,
+		},
+		{
+			name: "point off segment",
+			args: args{p1: [2]Float{0, 0}, p2: [2]Float{2, 2}, q1: [2]Float{3, 3}, q2: [2]Float{3, 3}},
+			want: Disjoint,
+		},
+		{
+			name: "same point",
+			args: args{p1: [2]Float{3, 3}, p2: [2]Float{3, 3}, q1: [2]Float{3, 3}, q2: [2]Float{3, 3}},
+			want: Touching,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.args
+			if got := SegmentsIntersect(a.p1, a.p2, a.q1, a.q2); got != tt.want {
+				t.Errorf("SegmentsIntersect() = %v, want %v", got, tt.want)
+			}
+			// the classification must not depend on the order of the arguments
+			if got := SegmentsIntersect(a.q2, a.q1, a.p2, a.p1); got != tt.want {
+				t.Errorf("SegmentsIntersect() swapped = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSegmentsIntersectNearlyCollinear(t *testing.T) {
+	// q2 sweeps past p2 in steps close to the resolution of Float, the
+	// segments must touch exactly when Orient2dExact says q2 is not right of p1p2.
+	p1 := [2]Float{0, 0}
+	p2 := [2]Float{20000, 30000}
+	q1 := [2]Float{20000, 0}
+	for y := Float(29999); y < 30001; y += 0.0009999 {
+		q2 := [2]Float{20000, y}
+		o := Orient2dExact(p1, p2, q2)
+		want := Disjoint
+		if o >= 0 {
+			want = Touching
+		}
+		if got := SegmentsIntersect(p1, p2, q1, q2); got != want {
+			t.Fatalf("SegmentsIntersect() = %v, want %v, q2=%v", got, want, q2)
+		}
+	}
+}
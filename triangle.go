@@ -0,0 +1,659 @@
+package predicates
+
+// Contact describes the shape of the set where two triangles meet.
+type Contact int
+
+const (
+	// NoContact means the triangles have no point in common.
+	NoContact Contact = iota
+	// PointContact means the triangles meet in a single point.
+	PointContact
+	// SegmentContact means the triangles meet along a segment of positive
+	// length.
+	SegmentContact
+	// AreaContact means the triangles are coplanar and share a region of
+	// positive area.
+	AreaContact
+)
+
+func (c Contact) String() string {
+	switch c {
+	case NoContact:
+		return "NoContact"
+	case PointContact:
+		return "PointContact"
+	case SegmentContact:
+		return "SegmentContact"
+	case AreaContact:
+		return "AreaContact"
+	}
+	return "Contact(?)"
+}
+
+// TrianglesIntersect classifies the intersection of the closed triangles p
+// and q in 3D. The kind is Disjoint if they have no point in common,
+// Touching if they meet only where at least one of them is on its boundary
+// (shared edges and vertices, a vertex or an edge lying on the other
+// triangle), Crossing if their relative interiors meet, and Overlapping if
+// they are coplanar and share a region of positive area. The contact gives
+// the shape of the intersection.
+//
+// This is the Guigue–Devillers test: the triangles are compared through
+// the signs of Orient3d of their vertices, and Orient2d in a coordinate
+// plane when a vertex, an edge or a whole triangle lies in the plane of the
+// other, so the result is exact. A degenerate triangle is treated as the
+// segment or point it covers, whose relative interior is the open segment
+// or the point itself.
+func TrianglesIntersect(p, q [3][3]Float) (IntersectionKind, Contact) {
+	pd, qd := Collinear3d(p[0], p[1], p[2]), Collinear3d(q[0], q[1], q[2])
+	switch {
+	case pd && qd:
+		a, b := span3(p)
+		c, d := span3(q)
+		return segments3(a, b, c, d)
+	case pd:
+		a, b := span3(p)
+		return segmentTriangle3(a, b, q)
+	case qd:
+		a, b := span3(q)
+		return segmentTriangle3(a, b, p)
+	}
+
+	sp, sq := planeSigns(q, p), planeSigns(p, q)
+	if sameSide(sp) || sameSide(sq) {
+		return Disjoint, NoContact
+	}
+	if sp == [3]int{} {
+		k := dropAxis(p[0], p[1], p[2])
+		return trianglesIntersect2(project(p, k), project(q, k))
+	}
+	if kind, c, ok := touchPlane(p, sp, q); ok {
+		return kind, c
+	}
+	if kind, c, ok := touchPlane(q, sq, p); ok {
+		return kind, c
+	}
+
+	// each triangle crosses the plane of the other: bring the vertex alone
+	// on its side first, and orient the other triangle so that this vertex
+	// is on its positive side
+	i, j := lone(sp), lone(sq)
+	p = [3][3]Float{p[i], p[(i+1)%3], p[(i+2)%3]}
+	q = [3][3]Float{q[j], q[(j+1)%3], q[(j+2)%3]}
+	if sp[i] < 0 {
+		q[1], q[2] = q[2], q[1]
+	}
+	if sq[j] < 0 {
+		p[1], p[2] = p[2], p[1]
+	}
+	// the segments where the triangles cut the line of the two planes
+	// overlap if neither of their ends is beyond the other segment
+	o1 := Orient3d(p[0], p[1], q[0], q[1])
+	o2 := Orient3d(p[0], p[2], q[2], q[0])
+	switch {
+	case o1 > 0 || o2 > 0:
+		return Disjoint, NoContact
+	case o1 == 0 || o2 == 0:
+		return Touching, PointContact
+	}
+	return Crossing, SegmentContact
+}
+
+// planeSigns returns the signs of Orient3d of the plane of t and each
+// vertex of p.
+func planeSigns(t, p [3][3]Float) [3]int {
+	var s [3]int
+	for i, v := range p {
+		o := Orient3d(t[0], t[1], t[2], v)
+		switch {
+		case o > 0:
+			s[i] = 1
+		case o < 0:
+			s[i] = -1
+		}
+	}
+	return s
+}
+
+// sameSide reports whether all the signs s are the same and not zero.
+func sameSide(s [3]int) bool {
+	return s[0] != 0 && s[0] == s[1] && s[1] == s[2]
+}
+
+// lone returns the index of a vertex that has a non-zero sign, and no other
+// vertex has the same sign.
+func lone(s [3]int) int {
+	for i, si := range s {
+		if si != 0 && s[(i+1)%3] != si && s[(i+2)%3] != si {
+			return i
+		}
+	}
+	panic("predicates: no lone vertex")
+}
+
+// touchPlane handles the triangle p meeting the plane of q only at a vertex
+// or along an edge, whose intersection with q is then the whole answer. It
+// returns false if p crosses the plane of q.
+func touchPlane(p [3][3]Float, s [3]int, q [3][3]Float) (IntersectionKind, Contact, bool) {
+	k := dropAxis(q[0], q[1], q[2])
+	t := project(q, k)
+	for i := 0; i < 3; i++ {
+		a, b, c := s[i], s[(i+1)%3], s[(i+2)%3]
+		switch {
+		case a == 0 && b == 0:
+			// an edge in the plane, on the boundary of p
+			if kind, contact := segmentTriangle2(project2(p[i], k), project2(p[(i+1)%3], k), t); kind != Disjoint {
+				return Touching, contact, true
+			}
+			return Disjoint, NoContact, true
+		case a == 0 && b == c:
+			// a single vertex in the plane
+			if PointInTriangle(t[0], t[1], t[2], project2(p[i], k)) != Outside {
+				return Touching, PointContact, true
+			}
+			return Disjoint, NoContact, true
+		}
+	}
+	return Disjoint, NoContact, false
+}
+
+// dropAxis returns the coordinate to drop so that the projection of the
+// triangle abc onto the plane of the other two is not degenerate, or -1 if
+// abc is degenerate.
+func dropAxis(a, b, c [3]Float) int {
+	for k := 0; k < 3; k++ {
+		if Orient2d(project2(a, k), project2(b, k), project2(c, k)) != 0 {
+			return k
+		}
+	}
+	return -1
+}
+
+// project2 drops the coordinate k of p, keeping the other two in cyclic
+// order.
+func project2(p [3]Float, k int) [2]Float {
+	return [2]Float{p[(k+1)%3], p[(k+2)%3]}
+}
+
+func project(t [3][3]Float, k int) [3][2]Float {
+	return [3][2]Float{project2(t[0], k), project2(t[1], k), project2(t[2], k)}
+}
+
+// span3 returns the lexicographically first and last vertices of a
+// degenerate triangle, the ends of the segment it covers.
+func span3(t [3][3]Float) ([3]Float, [3]Float) {
+	lo, hi := t[0], t[0]
+	for _, v := range t[1:] {
+		if lessXYZ(v, lo) {
+			lo = v
+		}
+		if lessXYZ(hi, v) {
+			hi = v
+		}
+	}
+	return lo, hi
+}
+
+// trianglesIntersect2 classifies the intersection of two non-degenerate
+// triangles in the plane. An edge line with the other triangle strictly
+// outside separates them; with the other triangle outside or on it, the
+// triangles touch along that line; and if no edge line separates them their
+// interiors overlap.
+func trianglesIntersect2(p, q [3][2]Float) (IntersectionKind, Contact) {
+	type separator struct {
+		t     [3][2]Float
+		edge  int
+		other [3][2]Float
+	}
+	var weak *separator
+	for _, pair := range [2][2][3][2]Float{{p, q}, {q, p}} {
+		t, u := pair[0], pair[1]
+		o := Orient2d(t[0], t[1], t[2])
+		for i := 0; i < 3; i++ {
+			inside, zero := false, false
+			for _, v := range u {
+				s := Orient2d(t[i], t[(i+1)%3], v)
+				inside = inside || s != 0 && (s > 0) == (o > 0)
+				zero = zero || s == 0
+			}
+			switch {
+			case inside:
+			case !zero:
+				return Disjoint, NoContact
+			case weak == nil:
+				weak = &separator{t, i, u}
+			}
+		}
+	}
+	if weak == nil {
+		return Overlapping, AreaContact
+	}
+	a, b := weak.t[weak.edge], weak.t[(weak.edge+1)%3]
+	var on [][2]Float
+	for _, v := range weak.other {
+		if Orient2d(a, b, v) == 0 {
+			on = append(on, v)
+		}
+	}
+	if len(on) == 2 && SegmentsIntersect(a, b, on[0], on[1]) == Overlapping {
+		return Touching, SegmentContact
+	}
+	return Touching, PointContact
+}
+
+// segmentTriangle2 classifies the intersection of the closed segment ab,
+// which may be a single point, with the non-degenerate triangle t in the
+// plane. It is Crossing if the open segment meets the open triangle.
+func segmentTriangle2(a, b [2]Float, t [3][2]Float) (IntersectionKind, Contact) {
+	if a == b {
+		switch PointInTriangle(t[0], t[1], t[2], a) {
+		case Outside:
+			return Disjoint, NoContact
+		case Inside:
+			return Crossing, PointContact
+		}
+		return Touching, PointContact
+	}
+	// the intersection is a segment, count the distinct points on it: the
+	// crossings with edges are inside both an edge and ab, so they differ
+	// from each other and from the vertices and endpoints
+	points := make(map[[2]Float]bool)
+	crossings := 0
+	for i := 0; i < 3; i++ {
+		c, d := t[i], t[(i+1)%3]
+		switch SegmentsIntersect(a, b, c, d) {
+		case Overlapping:
+			return Touching, SegmentContact
+		case Crossing:
+			crossings++
+		case Touching:
+			for _, v := range [][2]Float{a, b} {
+				if onSegment(c, d, v) {
+					points[v] = true
+				}
+			}
+			for _, v := range [][2]Float{c, d} {
+				if onSegment(a, b, v) {
+					points[v] = true
+				}
+			}
+		}
+	}
+	for _, v := range [][2]Float{a, b} {
+		if PointInTriangle(t[0], t[1], t[2], v) == Inside {
+			points[v] = true
+		}
+	}
+	switch len(points) + crossings {
+	case 0:
+		return Disjoint, NoContact
+	case 1:
+		return Touching, PointContact
+	}
+	// a piece of positive length not along an edge runs through the open
+	// triangle
+	return Crossing, SegmentContact
+}
+
+// segmentTriangle3 classifies the intersection of the closed segment ab,
+// which may be a single point, with the non-degenerate triangle t in 3D. It
+// is Crossing if the open segment meets the open triangle.
+func segmentTriangle3(a, b [3]Float, t [3][3]Float) (IntersectionKind, Contact) {
+	s := planeSigns(t, [3][3]Float{a, b, b})
+	k := dropAxis(t[0], t[1], t[2])
+	tp := project(t, k)
+	switch {
+	case s[0] == 0 && s[1] == 0:
+		return segmentTriangle2(project2(a, k), project2(b, k), tp)
+	case s[0] == 0 || s[1] == 0:
+		// an endpoint on the plane, the rest of the segment off it
+		v := a
+		if s[1] == 0 {
+			v = b
+		}
+		if PointInTriangle(tp[0], tp[1], tp[2], project2(v, k)) == Outside {
+			return Disjoint, NoContact
+		}
+		return Touching, PointContact
+	case s[0] == s[1]:
+		return Disjoint, NoContact
+	}
+	switch loc, _ := lineTriangle(a, b, t); loc {
+	case Outside:
+		return Disjoint, NoContact
+	case Inside:
+		return Crossing, PointContact
+	}
+	return Touching, PointContact
+}
+
+// segments3 classifies the intersection of the closed segments ab and cd in
+// 3D, either of which may be a single point. It is Crossing if their
+// relative interiors meet.
+func segments3(a, b, c, d [3]Float) (IntersectionKind, Contact) {
+	if a == b && c == d {
+		if a == c {
+			return Crossing, PointContact
+		}
+		return Disjoint, NoContact
+	}
+	if a == b {
+		a, b, c, d = c, d, a, b
+	}
+	if c == d {
+		switch {
+		case c == a || c == b:
+			return Touching, PointContact
+		case onSegment3(a, b, c):
+			return Crossing, PointContact
+		}
+		return Disjoint, NoContact
+	}
+	if Orient3d(a, b, c, d) != 0 {
+		return Disjoint, NoContact
+	}
+	// coplanar, compare them in a coordinate plane onto which their plane,
+	// or their line if they are collinear, projects without collapsing
+	k := dropAxis(a, b, c)
+	if k < 0 {
+		k = dropAxis(a, b, d)
+	}
+	if k < 0 {
+		for k = 0; project2(a, k) == project2(b, k); k++ {
+		}
+	}
+	switch SegmentsIntersect(project2(a, k), project2(b, k), project2(c, k), project2(d, k)) {
+	case Disjoint:
+		return Disjoint, NoContact
+	case Touching:
+		return Touching, PointContact
+	case Crossing:
+		return Crossing, PointContact
+	}
+	return Crossing, SegmentContact
+}
+
+// lineTriangle locates the point where the line through a and b, which
+// crosses the plane of the non-degenerate triangle t, meets it relative to
+// t. The line meets the closed triangle if it passes on the same side of
+// the lines of its three edges, or on them; that common side is returned
+// too, with the sign of Orient3d(a, b, x, y) for an edge xy. The point is
+// ahead of a, towards b, when the side is opposite to the side of a of the
+// plane of t.
+func lineTriangle(a, b [3]Float, t [3][3]Float) (Location, int) {
+	pos, neg := 0, 0
+	for i := 0; i < 3; i++ {
+		o := Orient3d(a, b, t[i], t[(i+1)%3])
+		if o > 0 {
+			pos++
+		} else if o < 0 {
+			neg++
+		}
+	}
+	side := 1
+	if neg > 0 {
+		side = -1
+	}
+	switch {
+	case pos > 0 && neg > 0:
+		return Outside, 0
+	case pos == 3 || neg == 3:
+		return Inside, side
+	case pos+neg == 2:
+		return OnEdge, side
+	}
+	return OnVertex, side
+}
+
+// SegmentTriangle locates where the closed segment pq meets the closed
+// triangle abc: Inside if it passes through the interior of the triangle,
+// OnEdge or OnVertex if it only meets its boundary, and Outside if it
+// misses it. When pq lies in the plane of abc, the result is the deepest of
+// the locations of the points they share. Every case is decided by the
+// signs of Orient3d, and of Orient2d in a coordinate plane for coplanar
+// points, so it agrees with them exactly.
+//
+// A segment whose endpoints coincide is a point, located as by
+// PointInTriangle. A degenerate (collinear) triangle is treated as the
+// union of its edges, so the result is OnVertex, OnEdge or Outside.
+func SegmentTriangle(p, q, a, b, c [3]Float) Location {
+	t := [3][3]Float{a, b, c}
+	if Collinear3d(a, b, c) {
+		return segmentSpan(p, q, t)
+	}
+	s := planeSigns(t, [3][3]Float{p, q, q})
+	k := dropAxis(a, b, c)
+	tp := project(t, k)
+	switch {
+	case s[0] == 0 && s[1] == 0:
+		return segmentLocation2(project2(p, k), project2(q, k), tp)
+	case s[0] == 0:
+		return PointInTriangle(tp[0], tp[1], tp[2], project2(p, k))
+	case s[1] == 0:
+		return PointInTriangle(tp[0], tp[1], tp[2], project2(q, k))
+	case s[0] == s[1]:
+		return Outside
+	}
+	loc, _ := lineTriangle(p, q, t)
+	return loc
+}
+
+// RayTriangle locates where the ray from o through d meets the closed
+// triangle abc, in the same way as SegmentTriangle. o and d must be
+// distinct. The ray includes its origin, so a ray starting on the triangle
+// always meets it.
+func RayTriangle(o, d, a, b, c [3]Float) Location {
+	t := [3][3]Float{a, b, c}
+	if Collinear3d(a, b, c) {
+		return raySpan(o, d, t)
+	}
+	s := planeSigns(t, [3][3]Float{o, d, d})
+	k := dropAxis(a, b, c)
+	tp := project(t, k)
+	if s[0] == 0 {
+		if s[1] == 0 {
+			return rayLocation2(project2(o, k), project2(d, k), tp)
+		}
+		return PointInTriangle(tp[0], tp[1], tp[2], project2(o, k))
+	}
+	loc, side := lineTriangle(o, d, t)
+	if loc != Outside && side == s[0] {
+		// the line meets the triangle behind o
+		return Outside
+	}
+	return loc
+}
+
+// segmentLocation2 is SegmentTriangle for a segment ab, which may be a
+// single point, and a non-degenerate triangle t in the plane.
+func segmentLocation2(a, b [2]Float, t [3][2]Float) Location {
+	kind, contact := segmentTriangle2(a, b, t)
+	switch {
+	case kind == Disjoint:
+		return Outside
+	case kind == Crossing:
+		return Inside
+	case contact == SegmentContact:
+		return OnEdge
+	}
+	// a single point on the boundary, a vertex if one is on ab
+	for _, v := range t {
+		if v == a || v == b || a != b && onSegment(a, b, v) {
+			return OnVertex
+		}
+	}
+	return OnEdge
+}
+
+// rayLocation2 is RayTriangle for a ray from o through d and a
+// non-degenerate triangle t in the plane. The line of the ray meets t in a
+// segment; o is either on it, or it is wholly ahead of o or behind it.
+func rayLocation2(o, d [2]Float, t [3][2]Float) Location {
+	var s [3]Float
+	pos, neg := 0, 0
+	for i, v := range t {
+		s[i] = Orient2d(o, d, v)
+		if s[i] > 0 {
+			pos++
+		} else if s[i] < 0 {
+			neg++
+		}
+	}
+	if pos == 3 || neg == 3 {
+		return Outside
+	}
+	at := PointInTriangle(t[0], t[1], t[2], o)
+	if at == Inside {
+		return Inside
+	}
+	// ahead reports whether the point w on the line of the ray is ahead of o
+	ahead := func(w [2]Float) bool { return lessXY(o, d) == lessXY(o, w) }
+	// inward reports whether the ray from o, on the line of the edge i, runs
+	// to the side of the triangle
+	inward := func(i int) bool {
+		u, v, w := t[i], t[(i+1)%3], t[(i+2)%3]
+		od, ow := Orient2d(u, v, d), Orient2d(u, v, w)
+		return od > 0 && ow > 0 || od < 0 && ow < 0
+	}
+	zeros := 3 - pos - neg
+	switch {
+	case pos > 0 && neg > 0:
+		// the line passes through the interior
+		if at != Outside {
+			for i := 0; i < 3; i++ {
+				if o == t[i] || at == OnEdge && onSegment(t[i], t[(i+1)%3], o) {
+					if inward(i) {
+						return Inside
+					}
+					return at
+				}
+			}
+		}
+		for i := range t {
+			if s[i] == 0 {
+				if ahead(t[i]) {
+					return Inside
+				}
+				return Outside
+			}
+		}
+		// no vertex on the line, compare with an edge it crosses
+		for i := range t {
+			u, v := t[i], t[(i+1)%3]
+			if s[i]*s[(i+1)%3] < 0 {
+				if Orient2d(o, u, d) > 0 == (Orient2d(o, u, v) > 0) {
+					return Inside
+				}
+				return Outside
+			}
+		}
+	case zeros == 2:
+		// the line of an edge
+		var ends [][2]Float
+		for i := range t {
+			if s[i] == 0 {
+				ends = append(ends, t[i])
+			}
+		}
+		switch {
+		case at == OnEdge:
+			return OnEdge
+		case at == OnVertex:
+			other := ends[0]
+			if other == o {
+				other = ends[1]
+			}
+			if ahead(other) {
+				return OnEdge
+			}
+			return OnVertex
+		case ahead(ends[0]):
+			return OnEdge
+		}
+		return Outside
+	default:
+		// through a single vertex
+		if at == OnVertex {
+			return OnVertex
+		}
+		for i := range t {
+			if s[i] == 0 && ahead(t[i]) {
+				return OnVertex
+			}
+		}
+	}
+	return Outside
+}
+
+// segmentSpan is SegmentTriangle for a degenerate triangle t.
+func segmentSpan(p, q [3]Float, t [3][3]Float) Location {
+	a, b := span3(t)
+	kind, contact := segments3(p, q, a, b)
+	if kind == Disjoint {
+		return Outside
+	}
+	if contact == PointContact {
+		for _, v := range t {
+			if v == p || v == q || p != q && onSegment3(p, q, v) {
+				return OnVertex
+			}
+		}
+	}
+	return OnEdge
+}
+
+// raySpan is RayTriangle for a degenerate triangle t.
+func raySpan(o, d [3]Float, t [3][3]Float) Location {
+	// onRay reports whether v, on the line of the ray, is on the ray
+	onRay := func(v [3]Float) bool { return v == o || lessXYZ(o, d) == lessXYZ(o, v) }
+	vertexOnRay := func() bool {
+		for _, v := range t {
+			if Collinear3d(o, d, v) && onRay(v) {
+				return true
+			}
+		}
+		return false
+	}
+	a, b := span3(t)
+	if Collinear3d(o, d, a) && Collinear3d(o, d, b) {
+		// along the line of the ray
+		switch ra, rb := onRay(a), onRay(b); {
+		case a == b && ra:
+			return OnVertex
+		case a == b || !ra && !rb:
+			return Outside
+		case ra && rb, ra && a != o, rb && b != o:
+			return OnEdge
+		}
+		return OnVertex
+	}
+	if a == b || Orient3d(o, d, a, b) != 0 {
+		return Outside
+	}
+	// the lines cross at one point, find it in a plane where they do not
+	// collapse
+	k := dropAxis(o, d, a)
+	if k < 0 {
+		k = dropAxis(o, d, b)
+	}
+	po, pd, pa, pb := project2(o, k), project2(d, k), project2(a, k), project2(b, k)
+	sa, sb := Orient2d(po, pd, pa), Orient2d(po, pd, pb)
+	switch {
+	case sa > 0 && sb > 0 || sa < 0 && sb < 0:
+		return Outside
+	case sa == 0 || sb == 0:
+		// at an end of the span
+		if vertexOnRay() {
+			return OnVertex
+		}
+		return Outside
+	}
+	if oa := Orient2d(po, pa, pb); oa != 0 && (Orient2d(po, pa, pd) > 0) != (oa > 0) {
+		// the crossing is behind o
+		return Outside
+	}
+	if vertexOnRay() {
+		return OnVertex
+	}
+	return OnEdge
+}
@@ -0,0 +1,426 @@
+package predicates
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+type vec3 [3]*big.Rat
+
+func ratVec(p [3]Float) vec3 {
+	var v vec3
+	for i := range v {
+		v[i] = new(big.Rat).SetFloat64(float64(p[i]))
+	}
+	return v
+}
+
+func (a vec3) sub(b vec3) vec3 {
+	var v vec3
+	for i := range v {
+		v[i] = new(big.Rat).Sub(a[i], b[i])
+	}
+	return v
+}
+
+func (a vec3) add(b vec3) vec3 {
+	var v vec3
+	for i := range v {
+		v[i] = new(big.Rat).Add(a[i], b[i])
+	}
+	return v
+}
+
+func (a vec3) scale(k *big.Rat) vec3 {
+	var v vec3
+	for i := range v {
+		v[i] = new(big.Rat).Mul(a[i], k)
+	}
+	return v
+}
+
+func (a vec3) dot(b vec3) *big.Rat {
+	s := new(big.Rat)
+	for i := range a {
+		s.Add(s, new(big.Rat).Mul(a[i], b[i]))
+	}
+	return s
+}
+
+func (a vec3) cross(b vec3) vec3 {
+	var v vec3
+	for i := range v {
+		j, k := (i+1)%3, (i+2)%3
+		v[i] = new(big.Rat).Sub(new(big.Rat).Mul(a[j], b[k]), new(big.Rat).Mul(a[k], b[j]))
+	}
+	return v
+}
+
+func (a vec3) equal(b vec3) bool {
+	return a[0].Cmp(b[0]) == 0 && a[1].Cmp(b[1]) == 0 && a[2].Cmp(b[2]) == 0
+}
+
+func (a vec3) zero() bool {
+	return a[0].Sign() == 0 && a[1].Sign() == 0 && a[2].Sign() == 0
+}
+
+// interior reports whether x, in the plane of the non-degenerate triangle t
+// with normal n, is in its open interior.
+func interior(t [3]vec3, n, x vec3) bool {
+	for i := 0; i < 3; i++ {
+		if n.dot(t[(i+1)%3].sub(t[i]).cross(x.sub(t[i]))).Sign() <= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// referenceTriangles classifies the intersection of two non-degenerate
+// triangles by computing it in rational arithmetic.
+func referenceTriangles(p, q [3][3]Float) (IntersectionKind, Contact) {
+	var tp, tq [3]vec3
+	for i := range p {
+		tp[i], tq[i] = ratVec(p[i]), ratVec(q[i])
+	}
+	np := tp[1].sub(tp[0]).cross(tp[2].sub(tp[0]))
+	nq := tq[1].sub(tq[0]).cross(tq[2].sub(tq[0]))
+	u := np.cross(nq)
+	if u.zero() {
+		if np.dot(tq[0].sub(tp[0])).Sign() != 0 {
+			return Disjoint, NoContact
+		}
+		// clip p by the half planes of q
+		poly := tp[:]
+		for i := 0; i < 3; i++ {
+			side := func(x vec3) *big.Rat {
+				return nq.dot(tq[(i+1)%3].sub(tq[i]).cross(x.sub(tq[i])))
+			}
+			var out []vec3
+			for j, b := range poly {
+				a := poly[(j+len(poly)-1)%len(poly)]
+				sa, sb := side(a), side(b)
+				if sa.Sign()*sb.Sign() < 0 {
+					k := new(big.Rat).Quo(sa, new(big.Rat).Sub(sa, sb))
+					out = append(out, a.add(b.sub(a).scale(k)))
+				}
+				if sb.Sign() >= 0 {
+					out = append(out, b)
+				}
+			}
+			poly = out
+		}
+		var points []vec3
+		for _, x := range poly {
+			dup := false
+			for _, y := range points {
+				dup = dup || x.equal(y)
+			}
+			if !dup {
+				points = append(points, x)
+			}
+		}
+		switch len(points) {
+		case 0:
+			return Disjoint, NoContact
+		case 1:
+			return Touching, PointContact
+		}
+		for _, x := range points[2:] {
+			if !points[1].sub(points[0]).cross(x.sub(points[0])).zero() {
+				return Overlapping, AreaContact
+			}
+		}
+		return Touching, SegmentContact
+	}
+
+	// the pieces of both triangles on the line of the planes
+	cut := func(t [3]vec3, n, o vec3) []vec3 {
+		var xs []vec3
+		for i := 0; i < 3; i++ {
+			a, b := t[i], t[(i+1)%3]
+			da, db := n.dot(a.sub(o)), n.dot(b.sub(o))
+			if da.Sign() == 0 {
+				xs = append(xs, a)
+			} else if da.Sign()*db.Sign() < 0 {
+				k := new(big.Rat).Quo(da, new(big.Rat).Sub(da, db))
+				xs = append(xs, a.add(b.sub(a).scale(k)))
+			}
+		}
+		return xs
+	}
+	xp, xq := cut(tp, nq, tq[0]), cut(tq, np, tp[0])
+	if len(xp) == 0 || len(xq) == 0 {
+		return Disjoint, NoContact
+	}
+	ends := func(xs []vec3) (lo, hi vec3) {
+		lo, hi = xs[0], xs[0]
+		for _, x := range xs {
+			if x.dot(u).Cmp(lo.dot(u)) < 0 {
+				lo = x
+			}
+			if x.dot(u).Cmp(hi.dot(u)) > 0 {
+				hi = x
+			}
+		}
+		return lo, hi
+	}
+	plo, phi := ends(xp)
+	qlo, qhi := ends(xq)
+	lo, hi := plo, phi
+	if qlo.dot(u).Cmp(lo.dot(u)) > 0 {
+		lo = qlo
+	}
+	if qhi.dot(u).Cmp(hi.dot(u)) < 0 {
+		hi = qhi
+	}
+	c := lo.dot(u).Cmp(hi.dot(u))
+	if c > 0 {
+		return Disjoint, NoContact
+	}
+	contact := SegmentContact
+	if c == 0 {
+		contact = PointContact
+	}
+	mid := lo.add(hi).scale(big.NewRat(1, 2))
+	if interior(tp, np, mid) && interior(tq, nq, mid) {
+		return Crossing, contact
+	}
+	return Touching, contact
+}
+
+func TestTrianglesIntersect(t *testing.T) {
+	base := [3][3]Float{{0, 0, 0}, {4, 0, 0}, {0, 4, 0}}
+	tests := []struct {
+		name    string
+		p, q    [3][3]Float
+		kind    IntersectionKind
+		contact Contact
+	}{
+		{"apart", base, [3][3]Float{{0, 0, 1}, {4, 0, 1}, {0, 4, 1}}, Disjoint, NoContact},
+		{"piercing", base, [3][3]Float{{1, 1, -1}, {1, 1, 1}, {2, 0.5, 1}}, Crossing, SegmentContact},
+		{"shared edge", base, [3][3]Float{{4, 0, 0}, {0, 4, 0}, {2, 2, 3}}, Touching, SegmentContact},
+		{"shared vertex", base, [3][3]Float{{0, 0, 0}, {-1, 0, 2}, {0, -1, 2}}, Touching, PointContact},
+		{"vertex on face", base, [3][3]Float{{1, 1, 0}, {1, 2, 3}, {2, 1, 3}}, Touching, PointContact},
+		{"edge on face", base, [3][3]Float{{1, 1, 0}, {2, 1, 0}, {1, 2, 3}}, Touching, SegmentContact},
+		{"edge across edge", base, [3][3]Float{{2, -1, 0}, {2, 1, 0}, {2, 0, 3}}, Touching, SegmentContact},
+		{"edges crossing", base, [3][3]Float{{2, 2, -1}, {2, 2, 1}, {5, 5, 0}}, Touching, PointContact},
+		{"same", base, base, Overlapping, AreaContact},
+		{"coplanar overlap", base, [3][3]Float{{1, 1, 0}, {5, 1, 0}, {1, 5, 0}}, Overlapping, AreaContact},
+		{"coplanar edge", base, [3][3]Float{{4, 0, 0}, {0, 4, 0}, {4, 4, 0}}, Touching, SegmentContact},
+		{"coplanar vertex", base, [3][3]Float{{4, 0, 0}, {5, 0, 0}, {5, 1, 0}}, Touching, PointContact},
+		{"coplanar apart", base, [3][3]Float{{3, 3, 0}, {5, 3, 0}, {3, 5, 0}}, Disjoint, NoContact},
+		{"segment through", base, [3][3]Float{{1, 1, -1}, {1, 1, 1}, {1, 1, 0}}, Crossing, PointContact},
+		{"segment on edge", base, [3][3]Float{{1, 0, 0}, {3, 0, 0}, {2, 0, 0}}, Touching, SegmentContact},
+		{"segment in face", base, [3][3]Float{{1, 1, 0}, {2, 1, 0}, {1, 1, 0}}, Crossing, SegmentContact},
+		{"point in face", base, [3][3]Float{{1, 1, 0}, {1, 1, 0}, {1, 1, 0}}, Crossing, PointContact},
+		{"point off", base, [3][3]Float{{1, 1, 1}, {1, 1, 1}, {1, 1, 1}}, Disjoint, NoContact},
+		{"segments crossing", [3][3]Float{{0, 0, 0}, {2, 2, 2}, {1, 1, 1}}, [3][3]Float{{0, 2, 2}, {2, 0, 0}, {0, 2, 2}}, Crossing, PointContact},
+		{"segments skew", [3][3]Float{{0, 0, 0}, {2, 2, 2}, {1, 1, 1}}, [3][3]Float{{0, 2, 0}, {2, 0, 0}, {0, 2, 0}}, Disjoint, NoContact},
+		{"segments overlap", [3][3]Float{{0, 0, 0}, {2, 2, 2}, {1, 1, 1}}, [3][3]Float{{1, 1, 1}, {3, 3, 3}, {3, 3, 3}}, Crossing, SegmentContact},
+		{"segments end to end", [3][3]Float{{0, 0, 0}, {2, 2, 2}, {1, 1, 1}}, [3][3]Float{{2, 2, 2}, {3, 3, 3}, {3, 3, 3}}, Touching, PointContact},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, pq := range [][2][3][3]Float{{tt.p, tt.q}, {tt.q, tt.p}} {
+				kind, contact := TrianglesIntersect(pq[0], pq[1])
+				if kind != tt.kind || contact != tt.contact {
+					t.Errorf("TrianglesIntersect(%v, %v) = %v, %v, want %v, %v", pq[0], pq[1], kind, contact, tt.kind, tt.contact)
+				}
+			}
+		})
+	}
+}
+
+func TestTrianglesIntersectRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20000; i++ {
+		// a small lattice makes touching and coplanar configurations common
+		var p, q [3][3]Float
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				p[j][k] = Float(r.Intn(4))
+				q[j][k] = Float(r.Intn(4))
+			}
+		}
+		if Collinear3d(p[0], p[1], p[2]) || Collinear3d(q[0], q[1], q[2]) {
+			continue
+		}
+		kind, contact := TrianglesIntersect(p, q)
+		wantKind, wantContact := referenceTriangles(p, q)
+		if kind != wantKind || contact != wantContact {
+			t.Fatalf("TrianglesIntersect(%v, %v) = %v, %v, want %v, %v", p, q, kind, contact, wantKind, wantContact)
+		}
+		// the result does not depend on the order or orientation
+		p[0], p[1] = p[1], p[0]
+		q = [3][3]Float{q[2], q[0], q[1]}
+		if k, c := TrianglesIntersect(q, p); k != kind || c != contact {
+			t.Fatalf("TrianglesIntersect(%v, %v) = %v, %v, want %v, %v", q, p, k, c, kind, contact)
+		}
+	}
+}
+
+// referenceSegmentTriangle locates where the points p + t (q - p), for t
+// from 0 to 1 or to infinity for a ray, meet the non-degenerate triangle
+// abc, in rational arithmetic.
+func referenceSegmentTriangle(p, q, a, b, c [3]Float, ray bool) Location {
+	tri := [3]vec3{ratVec(a), ratVec(b), ratVec(c)}
+	o, dir := ratVec(p), ratVec(q).sub(ratVec(p))
+	n := tri[1].sub(tri[0]).cross(tri[2].sub(tri[0]))
+	at := func(t *big.Rat) vec3 { return o.add(dir.scale(t)) }
+	// g returns the coefficients of n . ((v - u) x (x(t) - u)) as a
+	// function of t, positive inside the edge uv
+	g := func(i int) (*big.Rat, *big.Rat) {
+		u, v := tri[i], tri[(i+1)%3]
+		e := v.sub(u)
+		return n.dot(e.cross(o.sub(u))), n.dot(e.cross(dir))
+	}
+	locate := func(x vec3) Location {
+		zeros := 0
+		for i := 0; i < 3; i++ {
+			u, v := tri[i], tri[(i+1)%3]
+			s := n.dot(v.sub(u).cross(x.sub(u))).Sign()
+			if s < 0 {
+				return Outside
+			}
+			if s == 0 {
+				zeros++
+			}
+		}
+		return [3]Location{Inside, OnEdge, OnVertex}[zeros]
+	}
+	lo := new(big.Rat)
+	var hi *big.Rat
+	if !ray {
+		hi = big.NewRat(1, 1)
+	}
+	f0, f1 := n.dot(o.sub(tri[0])), n.dot(dir)
+	if f1.Sign() != 0 {
+		t := new(big.Rat).Neg(f0)
+		t.Quo(t, f1)
+		if t.Sign() < 0 || hi != nil && t.Cmp(hi) > 0 {
+			return Outside
+		}
+		return locate(at(t))
+	}
+	if f0.Sign() != 0 {
+		return Outside
+	}
+	for i := 0; i < 3; i++ {
+		g0, g1 := g(i)
+		switch g1.Sign() {
+		case 0:
+			if g0.Sign() < 0 {
+				return Outside
+			}
+		case 1:
+			// t >= -g0/g1
+			t := new(big.Rat).Quo(new(big.Rat).Neg(g0), g1)
+			if t.Cmp(lo) > 0 {
+				lo = t
+			}
+		default:
+			t := new(big.Rat).Quo(new(big.Rat).Neg(g0), g1)
+			if hi == nil || t.Cmp(hi) < 0 {
+				hi = t
+			}
+		}
+	}
+	if lo.Cmp(hi) > 0 {
+		return Outside
+	}
+	return locate(at(new(big.Rat).Mul(new(big.Rat).Add(lo, hi), big.NewRat(1, 2))))
+}
+
+func TestSegmentTriangle(t *testing.T) {
+	a, b, c := [3]Float{0, 0, 0}, [3]Float{4, 0, 0}, [3]Float{0, 4, 0}
+	tests := []struct {
+		name    string
+		p, q    [3]Float
+		segment Location
+		ray     Location
+	}{
+		{"through interior", [3]Float{1, 1, -1}, [3]Float{1, 1, 1}, Inside, Inside},
+		{"short of it", [3]Float{1, 1, 2}, [3]Float{1, 1, 1}, Outside, Inside},
+		{"away from it", [3]Float{1, 1, 1}, [3]Float{1, 1, 2}, Outside, Outside},
+		{"through edge", [3]Float{2, 0, -1}, [3]Float{2, 0, 1}, OnEdge, OnEdge},
+		{"through vertex", [3]Float{4, 0, -1}, [3]Float{4, 0, 1}, OnVertex, OnVertex},
+		{"ending inside", [3]Float{1, 1, 0}, [3]Float{1, 1, 1}, Inside, Inside},
+		{"ending on edge", [3]Float{1, 1, 1}, [3]Float{1, 0, 0}, OnEdge, OnEdge},
+		{"missing", [3]Float{5, 5, -1}, [3]Float{5, 5, 1}, Outside, Outside},
+		{"coplanar across", [3]Float{-1, 1, 0}, [3]Float{5, 1, 0}, Inside, Inside},
+		{"coplanar towards", [3]Float{-2, 1, 0}, [3]Float{-1, 1, 0}, Outside, Inside},
+		{"coplanar along edge", [3]Float{-1, 0, 0}, [3]Float{1, 0, 0}, OnEdge, OnEdge},
+		{"coplanar from vertex out", [3]Float{4, 0, 0}, [3]Float{5, 0, 0}, OnVertex, OnVertex},
+		{"coplanar from vertex in", [3]Float{4, 0, 0}, [3]Float{5, -1, 0}, OnVertex, OnVertex},
+		{"coplanar from vertex across", [3]Float{4, 0, 0}, [3]Float{3, 0.5, 0}, Inside, Inside},
+		{"coplanar from edge out", [3]Float{2, 0, 0}, [3]Float{2, -1, 0}, OnEdge, OnEdge},
+		{"coplanar touching vertex", [3]Float{3, -1, 0}, [3]Float{5, 1, 0}, OnVertex, OnVertex},
+		{"coplanar missing", [3]Float{5, 5, 0}, [3]Float{6, 5, 0}, Outside, Outside},
+		{"point inside", [3]Float{1, 1, 0}, [3]Float{1, 1, 0}, Inside, Inside},
+	}
+	for _, tt := range tests {
+		if got := SegmentTriangle(tt.p, tt.q, a, b, c); got != tt.segment {
+			t.Errorf("%s: SegmentTriangle(%v, %v) = %v, want %v", tt.name, tt.p, tt.q, got, tt.segment)
+		}
+		if got := SegmentTriangle(tt.q, tt.p, c, a, b); got != tt.segment {
+			t.Errorf("%s: SegmentTriangle(%v, %v) = %v, want %v", tt.name, tt.q, tt.p, got, tt.segment)
+		}
+		if tt.p == tt.q {
+			continue
+		}
+		if got := RayTriangle(tt.p, tt.q, a, b, c); got != tt.ray {
+			t.Errorf("%s: RayTriangle(%v, %v) = %v, want %v", tt.name, tt.p, tt.q, got, tt.ray)
+		}
+	}
+}
+
+func TestSegmentTriangleDegenerate(t *testing.T) {
+	// a degenerate triangle with its middle vertex at (2, 0, 0)
+	a, b, c := [3]Float{0, 0, 0}, [3]Float{2, 0, 0}, [3]Float{4, 0, 0}
+	tests := []struct {
+		name    string
+		p, q    [3]Float
+		segment Location
+		ray     Location
+	}{
+		{"across middle vertex", [3]Float{2, -1, 0}, [3]Float{2, 1, 0}, OnVertex, OnVertex},
+		{"across edge", [3]Float{1, -1, 1}, [3]Float{1, 1, -1}, OnEdge, OnEdge},
+		{"short of edge", [3]Float{1, -2, 0}, [3]Float{1, -1, 0}, Outside, OnEdge},
+		{"skew", [3]Float{1, -1, 1}, [3]Float{1, 1, 1}, Outside, Outside},
+		{"along", [3]Float{-1, 0, 0}, [3]Float{1, 0, 0}, OnEdge, OnEdge},
+		{"from end outwards", [3]Float{4, 0, 0}, [3]Float{5, 0, 0}, OnVertex, OnVertex},
+		{"towards end", [3]Float{6, 0, 0}, [3]Float{5, 0, 0}, Outside, OnEdge},
+		{"behind", [3]Float{6, 0, 0}, [3]Float{7, 0, 0}, Outside, Outside},
+	}
+	for _, tt := range tests {
+		if got := SegmentTriangle(tt.p, tt.q, a, b, c); got != tt.segment {
+			t.Errorf("%s: SegmentTriangle(%v, %v) = %v, want %v", tt.name, tt.p, tt.q, got, tt.segment)
+		}
+		if got := RayTriangle(tt.p, tt.q, b, c, a); got != tt.ray {
+			t.Errorf("%s: RayTriangle(%v, %v) = %v, want %v", tt.name, tt.p, tt.q, got, tt.ray)
+		}
+	}
+}
+
+func TestSegmentTriangleRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 20000; i++ {
+		var v [5][3]Float
+		for j := range v {
+			for k := 0; k < 3; k++ {
+				v[j][k] = Float(r.Intn(4))
+			}
+		}
+		p, q, a, b, c := v[0], v[1], v[2], v[3], v[4]
+		if p == q || Collinear3d(a, b, c) {
+			continue
+		}
+		if got, want := SegmentTriangle(p, q, a, b, c), referenceSegmentTriangle(p, q, a, b, c, false); got != want {
+			t.Fatalf("SegmentTriangle(%v, %v, %v, %v, %v) = %v, want %v", p, q, a, b, c, got, want)
+		}
+		if got, want := RayTriangle(p, q, a, b, c), referenceSegmentTriangle(p, q, a, b, c, true); got != want {
+			t.Fatalf("RayTriangle(%v, %v, %v, %v, %v) = %v, want %v", p, q, a, b, c, got, want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package predicates
+
+// TriangleMesh is a triangulation in the plane, as seen by LocateTriangle.
+type TriangleMesh interface {
+	// Len returns the number of triangles, numbered from 0.
+	Len() int
+	// Triangle returns the vertices of the triangle t in counterclockwise
+	// order and its neighbours: n[i] is the triangle across the edge
+	// opposite v[i], or -1 if that edge is on the boundary.
+	Triangle(t int) (v [3][2]Float, n [3]int)
+}
+
+// TetMesh is a tetrahedralization, as seen by LocateTetrahedron.
+type TetMesh interface {
+	// Len returns the number of tetrahedra, numbered from 0.
+	Len() int
+	// Tetrahedron returns the vertices of the tetrahedron t, positively
+	// oriented (Orient3d(v[0], v[1], v[2], v[3]) > 0), and its neighbours:
+	// n[i] is the tetrahedron across the face opposite v[i], or -1 if that
+	// face is on the boundary.
+	Tetrahedron(t int) (v [4][3]Float, n [4]int)
+}
+
+// LocateTriangle finds the triangle of m containing p with a visibility
+// walk from the triangle start: from each triangle it crosses an edge that
+// has p strictly on its other side, decided by Orient2d, until there is
+// none. It returns the triangle and the location of p in it.
+//
+// The edges are tried from a pseudo-random first one, which keeps the walk
+// from cycling in triangulations that are not Delaunay, and if the walk
+// still takes more steps than there are triangles to spare, every triangle
+// is tested instead, so the search always terminates. If the walk reaches
+// the boundary with p beyond it, the result is that boundary triangle and
+// Outside; p may still be in the mesh if its union is not convex. An empty
+// mesh gives -1 and Outside.
+func LocateTriangle(m TriangleMesh, start int, p [2]Float) (int, Location) {
+	n := m.Len()
+	if n == 0 {
+		return -1, Outside
+	}
+	if start < 0 || start >= n {
+		start = 0
+	}
+	t := start
+	seed := uint32(start)*2654435761 + 1
+	for step := 0; step < 4*n+64; step++ {
+		v, nb := m.Triangle(t)
+		seed = xorshift(seed)
+		next, boundary := -1, false
+		for j := 0; j < 3; j++ {
+			i := (int(seed%3) + j) % 3
+			if Orient2d(v[(i+1)%3], v[(i+2)%3], p) < 0 {
+				if nb[i] >= 0 {
+					next = nb[i]
+					break
+				}
+				boundary = true
+			}
+		}
+		switch {
+		case next >= 0:
+			t = next
+		case boundary:
+			return t, Outside
+		default:
+			return t, PointInTriangle(v[0], v[1], v[2], p)
+		}
+	}
+	for t := 0; t < n; t++ {
+		v, _ := m.Triangle(t)
+		if loc := PointInTriangle(v[0], v[1], v[2], p); loc != Outside {
+			return t, loc
+		}
+	}
+	return -1, Outside
+}
+
+// LocateTetrahedron is the analogue of LocateTriangle for tetrahedral
+// meshes, crossing faces with p strictly on their other side as decided by
+// Orient3d.
+func LocateTetrahedron(m TetMesh, start int, p [3]Float) (int, Location) {
+	n := m.Len()
+	if n == 0 {
+		return -1, Outside
+	}
+	if start < 0 || start >= n {
+		start = 0
+	}
+	t := start
+	seed := uint32(start)*2654435761 + 1
+	for step := 0; step < 4*n+64; step++ {
+		v, nb := m.Tetrahedron(t)
+		seed = xorshift(seed)
+		next, boundary := -1, false
+		for j := 0; j < 4; j++ {
+			i := (int(seed%4) + j) % 4
+			// p replacing v[i] gives a negative orientation when it is
+			// beyond the face opposite v[i]
+			w := v
+			w[i] = p
+			if Orient3d(w[0], w[1], w[2], w[3]) < 0 {
+				if nb[i] >= 0 {
+					next = nb[i]
+					break
+				}
+				boundary = true
+			}
+		}
+		switch {
+		case next >= 0:
+			t = next
+		case boundary:
+			return t, Outside
+		default:
+			return t, PointInTetrahedron(v[0], v[1], v[2], v[3], p)
+		}
+	}
+	for t := 0; t < n; t++ {
+		v, _ := m.Tetrahedron(t)
+		if loc := PointInTetrahedron(v[0], v[1], v[2], v[3], p); loc != Outside {
+			return t, loc
+		}
+	}
+	return -1, Outside
+}
+
+// xorshift advances a 32-bit xorshift generator.
+func xorshift(x uint32) uint32 {
+	x ^= x << 13
+	x ^= x >> 17
+	x ^= x << 5
+	return x
+}
@@ -0,0 +1,258 @@
+package predicates
+
+import (
+	"sort"
+	"testing"
+)
+
+// triMesh is a TriangleMesh over indexed triangles.
+type triMesh struct {
+	points [][2]Float
+	tris   [][3]int
+	nb     [][3]int
+}
+
+func newTriMesh(points [][2]Float, tris [][3]int) *triMesh {
+	m := &triMesh{points: points, tris: tris, nb: make([][3]int, len(tris))}
+	edges := make(map[[2]int]int)
+	for t, tri := range tris {
+		for i := 0; i < 3; i++ {
+			edges[[2]int{tri[(i+1)%3], tri[(i+2)%3]}] = t
+		}
+	}
+	for t, tri := range tris {
+		for i := 0; i < 3; i++ {
+			m.nb[t][i] = -1
+			if u, ok := edges[[2]int{tri[(i+2)%3], tri[(i+1)%3]}]; ok {
+				m.nb[t][i] = u
+			}
+		}
+	}
+	return m
+}
+
+func (m *triMesh) Len() int { return len(m.tris) }
+
+func (m *triMesh) Triangle(t int) (v [3][2]Float, n [3]int) {
+	for i, k := range m.tris[t] {
+		v[i] = m.points[k]
+	}
+	return v, m.nb[t]
+}
+
+// gridMesh triangulates the n by n lattice with jittered interior points
+// and random diagonals, which is not Delaunay in general.
+func gridMesh(n int) *triMesh {
+	var points [][2]Float
+	for j := 0; j <= n; j++ {
+		for i := 0; i <= n; i++ {
+			p := [2]Float{Float(i), Float(j)}
+			if i > 0 && i < n && j > 0 && j < n {
+				p[0] += Float(random()%5-2) / 8
+				p[1] += Float(random()%5-2) / 8
+			}
+			points = append(points, p)
+		}
+	}
+	var tris [][3]int
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			a, b := j*(n+1)+i, j*(n+1)+i+1
+			c, d := b+n+1, a+n+1
+			if random()%2 == 0 {
+				tris = append(tris, [3]int{a, b, c}, [3]int{a, c, d})
+			} else {
+				tris = append(tris, [3]int{a, b, d}, [3]int{b, c, d})
+			}
+		}
+	}
+	return newTriMesh(points, tris)
+}
+
+func TestLocateTriangle(t *testing.T) {
+	const n = 8
+	m := gridMesh(n)
+	for i := 0; i < 5000; i++ {
+		var p [2]Float
+		if i%2 == 0 {
+			// lattice points fall on vertices and edges
+			p = [2]Float{Float(random()%(4*n+9)-4) / 4, Float(random()%(4*n+9)-4) / 4}
+		} else {
+			p = [2]Float{Float(random()%(1<<20)) / (1 << 20) * (n + 2), Float(random()%(1<<20)) / (1 << 20) * (n + 2)}
+			p[0]--
+			p[1]--
+		}
+		start := int(random() % int32(m.Len()))
+		tri, loc := LocateTriangle(m, start, p)
+		inside := p[0] >= 0 && p[0] <= n && p[1] >= 0 && p[1] <= n
+		if !inside {
+			if loc != Outside {
+				t.Errorf("LocateTriangle(%v) = %d, %v, want Outside", p, tri, loc)
+			}
+			continue
+		}
+		if tri < 0 {
+			t.Errorf("LocateTriangle(%v) = %d, %v", p, tri, loc)
+			continue
+		}
+		v, _ := m.Triangle(tri)
+		if want := PointInTriangle(v[0], v[1], v[2], p); loc == Outside || loc != want {
+			t.Errorf("LocateTriangle(%v) = %d, %v, want %v", p, tri, loc, want)
+		}
+	}
+}
+
+func TestLocateTriangleTerminates(t *testing.T) {
+	// every neighbour is triangle 0, so the walk can never leave it
+	m := gridMesh(4)
+	for i := range m.nb {
+		m.nb[i] = [3]int{0, 0, 0}
+	}
+	p := [2]Float{3.5, 3.5}
+	tri, loc := LocateTriangle(m, 0, p)
+	if tri < 0 || loc == Outside {
+		t.Fatalf("LocateTriangle(%v) = %d, %v", p, tri, loc)
+	}
+	v, _ := m.Triangle(tri)
+	if want := PointInTriangle(v[0], v[1], v[2], p); loc != want {
+		t.Errorf("LocateTriangle(%v) = %d, %v, want %v", p, tri, loc, want)
+	}
+	if tri, loc := LocateTriangle(m, 0, [2]Float{9, 9}); tri != -1 || loc != Outside {
+		t.Errorf("LocateTriangle() = %d, %v, want -1, Outside", tri, loc)
+	}
+	if tri, loc := LocateTriangle(newTriMesh(nil, nil), 0, p); tri != -1 || loc != Outside {
+		t.Errorf("LocateTriangle(empty) = %d, %v, want -1, Outside", tri, loc)
+	}
+}
+
+// tetMesh is a TetMesh over indexed tetrahedra.
+type tetMesh struct {
+	points [][3]Float
+	tets   [][4]int
+	nb     [][4]int
+}
+
+func newTetMesh(points [][3]Float, tets [][4]int) *tetMesh {
+	m := &tetMesh{points: points, tets: tets, nb: make([][4]int, len(tets))}
+	faces := make(map[[3]int][]int)
+	key := func(tet [4]int, i int) [3]int {
+		f := [3]int{tet[(i+1)%4], tet[(i+2)%4], tet[(i+3)%4]}
+		sort.Ints(f[:])
+		return f
+	}
+	for t, tet := range tets {
+		for i := 0; i < 4; i++ {
+			faces[key(tet, i)] = append(faces[key(tet, i)], t)
+		}
+	}
+	for t, tet := range tets {
+		for i := 0; i < 4; i++ {
+			m.nb[t][i] = -1
+			for _, u := range faces[key(tet, i)] {
+				if u != t {
+					m.nb[t][i] = u
+				}
+			}
+		}
+	}
+	return m
+}
+
+func (m *tetMesh) Len() int { return len(m.tets) }
+
+func (m *tetMesh) Tetrahedron(t int) (v [4][3]Float, n [4]int) {
+	for i, k := range m.tets[t] {
+		v[i] = m.points[k]
+	}
+	return v, m.nb[t]
+}
+
+// cubeMesh splits each cell of the n by n by n lattice into the six
+// tetrahedra around its main diagonal.
+func cubeMesh(n int) *tetMesh {
+	var points [][3]Float
+	index := func(i, j, k int) int { return (k*(n+1)+j)*(n+1) + i }
+	for k := 0; k <= n; k++ {
+		for j := 0; j <= n; j++ {
+			for i := 0; i <= n; i++ {
+				points = append(points, [3]Float{Float(i), Float(j), Float(k)})
+			}
+		}
+	}
+	axes := [][3]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}}
+	var tets [][4]int
+	for k := 0; k < n; k++ {
+		for j := 0; j < n; j++ {
+			for i := 0; i < n; i++ {
+				for _, ax := range axes {
+					c := [3]int{i, j, k}
+					var tet [4]int
+					tet[0] = index(c[0], c[1], c[2])
+					for s := 0; s < 3; s++ {
+						c[ax[s]]++
+						tet[s+1] = index(c[0], c[1], c[2])
+					}
+					if Orient3d(points[tet[0]], points[tet[1]], points[tet[2]], points[tet[3]]) < 0 {
+						tet[2], tet[3] = tet[3], tet[2]
+					}
+					tets = append(tets, tet)
+				}
+			}
+		}
+	}
+	return newTetMesh(points, tets)
+}
+
+func TestLocateTetrahedron(t *testing.T) {
+	const n = 4
+	m := cubeMesh(n)
+	for i := 0; i < 5000; i++ {
+		var p [3]Float
+		for k := range p {
+			if i%2 == 0 {
+				p[k] = Float(random()%(2*n+5)-2) / 2
+			} else {
+				p[k] = Float(random()%(1<<20))/(1<<20)*(n+2) - 1
+			}
+		}
+		start := int(random() % int32(m.Len()))
+		tet, loc := LocateTetrahedron(m, start, p)
+		inside := true
+		for k := range p {
+			inside = inside && p[k] >= 0 && p[k] <= n
+		}
+		if !inside {
+			if loc != Outside {
+				t.Errorf("LocateTetrahedron(%v) = %d, %v, want Outside", p, tet, loc)
+			}
+			continue
+		}
+		if tet < 0 {
+			t.Errorf("LocateTetrahedron(%v) = %d, %v", p, tet, loc)
+			continue
+		}
+		v, _ := m.Tetrahedron(tet)
+		if want := PointInTetrahedron(v[0], v[1], v[2], v[3], p); loc == Outside || loc != want {
+			t.Errorf("LocateTetrahedron(%v) = %d, %v, want %v", p, tet, loc, want)
+		}
+	}
+}
+
+func TestLocateTetrahedronTerminates(t *testing.T) {
+	m := cubeMesh(3)
+	for i := range m.nb {
+		m.nb[i] = [4]int{0, 0, 0, 0}
+	}
+	p := [3]Float{2.5, 2.25, 2.75}
+	tet, loc := LocateTetrahedron(m, 0, p)
+	if tet < 0 || loc == Outside {
+		t.Fatalf("LocateTetrahedron(%v) = %d, %v", p, tet, loc)
+	}
+	v, _ := m.Tetrahedron(tet)
+	if want := PointInTetrahedron(v[0], v[1], v[2], v[3], p); loc != want {
+		t.Errorf("LocateTetrahedron(%v) = %d, %v, want %v", p, tet, loc, want)
+	}
+	if tet, loc := LocateTetrahedron(m, 0, [3]Float{9, 9, 9}); tet != -1 || loc != Outside {
+		t.Errorf("LocateTetrahedron() = %d, %v, want -1, Outside", tet, loc)
+	}
+}